@@ -0,0 +1,198 @@
+package app
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SARIF renders the findings of a summary as a SARIF 2.1.0 log (https://sarifweb.azurewebsites.net),
+// which code scanning tools such as GitHub's show inline on pull requests.
+func SARIF(summary *Summary) ([]byte, error) {
+	type message struct {
+		Text string `json:"text"`
+	}
+	type rule struct {
+		ID               string  `json:"id"`
+		ShortDescription message `json:"shortDescription"`
+	}
+	type driver struct {
+		Name           string `json:"name"`
+		InformationURI string `json:"informationUri"`
+		Rules          []rule `json:"rules"`
+	}
+	type tool struct {
+		Driver driver `json:"driver"`
+	}
+	type region struct {
+		StartLine int `json:"startLine"`
+	}
+	type artifactLocation struct {
+		URI string `json:"uri"`
+	}
+	type physicalLocation struct {
+		ArtifactLocation artifactLocation `json:"artifactLocation"`
+		Region           *region          `json:"region,omitempty"`
+	}
+	type location struct {
+		PhysicalLocation physicalLocation `json:"physicalLocation"`
+	}
+	type result struct {
+		RuleID    string     `json:"ruleId"`
+		Level     string     `json:"level"`
+		Message   message    `json:"message"`
+		Locations []location `json:"locations"`
+	}
+	type run struct {
+		Tool    tool     `json:"tool"`
+		Results []result `json:"results"`
+	}
+	type log struct {
+		Schema  string `json:"$schema"`
+		Version string `json:"version"`
+		Runs    []run  `json:"runs"`
+	}
+
+	ruleIDs := make([]string, 0, len(FindingRules))
+	for id := range FindingRules {
+		ruleIDs = append(ruleIDs, id)
+	}
+	sort.Strings(ruleIDs)
+	rules := make([]rule, 0, len(ruleIDs))
+	for _, id := range ruleIDs {
+		rules = append(rules, rule{ID: id, ShortDescription: message{Text: FindingRules[id]}})
+	}
+
+	results := []result{}
+	for _, finding := range summary.Findings {
+		loc := physicalLocation{ArtifactLocation: artifactLocation{URI: finding.File}}
+		if finding.Line > 0 {
+			loc.Region = &region{StartLine: finding.Line}
+		}
+		results = append(results, result{
+			RuleID:    finding.Rule,
+			Level:     finding.Level,
+			Message:   message{Text: finding.Message},
+			Locations: []location{{PhysicalLocation: loc}},
+		})
+	}
+
+	body, err := json.MarshalIndent(log{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []run{{
+			Tool: tool{Driver: driver{
+				Name:           "tdiff",
+				InformationURI: "https://github.com/alecholmes/tdiff",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(body, '\n'), nil
+}
+
+// GitHubAnnotations renders the findings of a summary as GitHub Actions workflow commands,
+// e.g. "::warning file=a.go,line=3,title=new-import::New import of b", which annotate
+// pull requests inline.
+func GitHubAnnotations(summary *Summary) ([]byte, error) {
+	commands := map[string]string{
+		LevelNote:    "notice",
+		LevelWarning: "warning",
+		LevelError:   "error",
+	}
+
+	var buf bytes.Buffer
+	for _, finding := range summary.Findings {
+		command, ok := commands[finding.Level]
+		if !ok {
+			return nil, fmt.Errorf("Unknown finding level `%s`", finding.Level)
+		}
+
+		properties := []string{"file=" + escapeAnnotationProperty(finding.File)}
+		if finding.Line > 0 {
+			properties = append(properties, fmt.Sprintf("line=%d", finding.Line))
+		}
+		properties = append(properties, "title="+escapeAnnotationProperty(finding.Rule))
+
+		fmt.Fprintf(&buf, "::%s %s::%s\n", command, strings.Join(properties, ","), escapeAnnotationData(finding.Message))
+	}
+
+	return buf.Bytes(), nil
+}
+
+// escapeAnnotationData escapes the message of a GitHub Actions workflow command.
+func escapeAnnotationData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeAnnotationProperty escapes a property value of a GitHub Actions workflow command.
+func escapeAnnotationProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// GitLabCodeQuality renders the findings of a summary as a GitLab code quality report
+// (https://docs.gitlab.com/ee/ci/testing/code_quality.html), which merge requests show inline.
+func GitLabCodeQuality(summary *Summary) ([]byte, error) {
+	severities := map[string]string{
+		LevelNote:    "info",
+		LevelWarning: "minor",
+		LevelError:   "major",
+	}
+
+	type lines struct {
+		Begin int `json:"begin"`
+	}
+	type location struct {
+		Path  string `json:"path"`
+		Lines lines  `json:"lines"`
+	}
+	type issue struct {
+		Description string   `json:"description"`
+		CheckName   string   `json:"check_name"`
+		Fingerprint string   `json:"fingerprint"`
+		Severity    string   `json:"severity"`
+		Location    location `json:"location"`
+	}
+
+	issues := []issue{}
+	for _, finding := range summary.Findings {
+		severity, ok := severities[finding.Level]
+		if !ok {
+			return nil, fmt.Errorf("Unknown finding level `%s`", finding.Level)
+		}
+
+		// Lines are required, so findings about whole files are reported on the first line.
+		line := finding.Line
+		if line <= 0 {
+			line = 1
+		}
+
+		// The fingerprint identifies the finding across runs, so it excludes the line, which
+		// unrelated changes can move.
+		fingerprint := sha256.Sum256([]byte(strings.Join([]string{finding.Rule, finding.File, finding.Message}, "\x00")))
+
+		issues = append(issues, issue{
+			Description: finding.Message,
+			CheckName:   finding.Rule,
+			Fingerprint: hex.EncodeToString(fingerprint[:]),
+			Severity:    severity,
+			Location:    location{Path: finding.File, Lines: lines{Begin: line}},
+		})
+	}
+
+	body, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(body, '\n'), nil
+}
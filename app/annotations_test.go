@@ -0,0 +1,92 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// testFindings returns sorted findings of every level, including findings without a line and with
+// characters that need escaping.
+func testFindings() []*Finding {
+	return []*Finding{
+		{Rule: RuleBreakingAPIChange, Level: LevelError, Message: "Breaking API change: removed func example.com/repo/lib.Helper", File: "lib"},
+		{Rule: RuleChangedFile, Level: LevelNote, Message: "lib/lib.go changed in example.com/repo/lib", File: "lib/lib.go"},
+		{Rule: RuleNewImport, Level: LevelWarning, Message: "New import of example.com/repo/util", File: "lib/lib.go", Line: 4},
+		{Rule: RulePolicyViolation, Level: LevelError, Message: "Policy no-util violated: 100%\nof lib > util", File: "lib/a,b:c.go", Line: 7},
+	}
+}
+
+func TestFindingsGolden(t *testing.T) {
+	testCases := []struct {
+		name   string
+		golden string
+		format func(*Summary) ([]byte, error)
+	}{
+		{name: "sarif", golden: "findings_sarif.golden.json", format: SARIF},
+		{name: "github", golden: "findings_github.golden.txt", format: GitHubAnnotations},
+		{name: "gitlab", golden: "findings_codequality.golden.json", format: GitLabCodeQuality},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("format=%s", tc.name), func(t *testing.T) {
+			actual, err := tc.format(&Summary{Findings: testFindings()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, tc.golden, actual)
+		})
+	}
+}
+
+func TestFindingsUnknownLevel(t *testing.T) {
+	summary := &Summary{Findings: []*Finding{{Rule: RuleChangedFile, Level: "fatal", File: "a.go"}}}
+	if _, err := GitHubAnnotations(summary); err == nil {
+		t.Fatal("Expected an error for an unknown level in GitHub annotations")
+	}
+	if _, err := GitLabCodeQuality(summary); err == nil {
+		t.Fatal("Expected an error for an unknown level in the GitLab code quality report")
+	}
+}
+
+func TestEscapeAnnotation(t *testing.T) {
+	testCases := []struct {
+		value            string
+		expectedData     string
+		expectedProperty string
+	}{
+		{value: "a/b.go", expectedData: "a/b.go", expectedProperty: "a/b.go"},
+		{value: "a,b:c.go", expectedData: "a,b:c.go", expectedProperty: "a%2Cb%3Ac.go"},
+		{value: "100%\r\nnext", expectedData: "100%25%0D%0Anext", expectedProperty: "100%25%0D%0Anext"},
+		{value: "%2C", expectedData: "%252C", expectedProperty: "%252C"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("value=%q", tc.value), func(t *testing.T) {
+			if actual := escapeAnnotationData(tc.value); actual != tc.expectedData {
+				t.Fatalf("Expected data %q but got %q", tc.expectedData, actual)
+			}
+			if actual := escapeAnnotationProperty(tc.value); actual != tc.expectedProperty {
+				t.Fatalf("Expected property %q but got %q", tc.expectedProperty, actual)
+			}
+		})
+	}
+}
+
+func TestSortFindings(t *testing.T) {
+	expected := []*Finding{
+		{Rule: RuleBreakingAPIChange, Message: "b", File: "a"},
+		{Rule: RuleChangedFile, Message: "b", File: "a/a.go"},
+		{Rule: RuleChangedFile, Message: "c", File: "a/a.go"},
+		{Rule: RulePolicyViolation, Message: "a", File: "a/a.go", Line: 3},
+		{Rule: RuleLayerViolation, Message: "a", File: "a/a.go", Line: 12},
+		{Rule: RuleNewImport, Message: "a", File: "a/a.go", Line: 12},
+		{Rule: RuleChangedFile, Message: "a", File: "b/b.go"},
+	}
+	findings := []*Finding{expected[6], expected[5], expected[3], expected[2], expected[0], expected[4], expected[1]}
+
+	sortFindings(findings)
+	if !reflect.DeepEqual(expected, findings) {
+		t.Fatalf("Expected %v but got %v", expected, findings)
+	}
+}
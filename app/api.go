@@ -0,0 +1,106 @@
+package app
+
+import (
+	"bytes"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/alecholmes/tdiff/source"
+)
+
+// determineAPIChanges compares the exported API of each relevant package between
+// the given SHA and HEAD.
+func (d *diff) determineAPIChanges() error {
+	for _, pkg := range d.summary.Packages {
+		oldAPI, err := d.packageAPI(pkg.ImportPath, d.summary.SHA)
+		if err != nil {
+			return err
+		}
+		newAPI, err := d.packageAPI(pkg.ImportPath, "HEAD")
+		if err != nil {
+			return err
+		}
+
+		pkg.APIChanges = source.CompareAPI(oldAPI, newAPI)
+	}
+
+	return nil
+}
+
+// packageAPI returns the exported API of a package as of the commit of the given SHA.
+// Test files, and files excluded by build constraints for the default build context, are ignored.
+func (d *diff) packageAPI(pkg, sha string) (source.API, error) {
+	contents, err := d.packageSources(pkg, sha)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var parsed []*ast.File
+	for file, body := range contents {
+		parsedFile, err := parser.ParseFile(fset, file, body, 0)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, parsedFile)
+	}
+
+	return source.ExtractAPI(fset, parsed)
+}
+
+// packageSources returns the contents of the Go files of a package as of the commit of the given SHA,
+// by file. Test files, and files excluded by build constraints for the default build context, are ignored.
+func (d *diff) packageSources(pkg, sha string) (map[string][]byte, error) {
+	dir := d.packageDir(pkg)
+	files, err := d.git.ListFiles(sha, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make(map[string][]byte)
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".go") || strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		if contents[file], err = d.git.FileAt(sha, file); err != nil {
+			return nil, err
+		}
+	}
+
+	buildCtx := build.Default
+	buildCtx.JoinPath = path.Join
+	buildCtx.OpenFile = func(file string) (io.ReadCloser, error) {
+		body, ok := contents[file]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	matched := make(map[string][]byte)
+	for file, body := range contents {
+		if match, err := buildCtx.MatchFile(path.Dir(file), path.Base(file)); err != nil {
+			return nil, err
+		} else if match {
+			matched[file] = body
+		}
+	}
+
+	return matched, nil
+}
+
+// packageDir returns the directory of a package relative to the root of the Git repository.
+func (d *diff) packageDir(pkg string) string {
+	if pkg == d.packagePrefix {
+		return "."
+	}
+
+	return strings.TrimPrefix(pkg, d.packagePrefix+"/")
+}
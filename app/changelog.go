@@ -0,0 +1,93 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Semantic version bumps.
+const (
+	BumpMajor = "major"
+	BumpMinor = "minor"
+	BumpPatch = "patch"
+	BumpNone  = "none"
+)
+
+var semver = regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)$`)
+
+// SemverBump returns the semantic version bump suggested by Conventional Commits for the given commits:
+// major for breaking changes, minor for features, patch for fixes and performance improvements,
+// and none otherwise. Commits that do not follow Conventional Commits do not affect the bump.
+func SemverBump(commits []*Commit) string {
+	bump := BumpNone
+	for _, commit := range commits {
+		bump = commitBump(bump, commit)
+	}
+
+	return bump
+}
+
+// commitBump returns the larger of a bump and the bump suggested by a commit.
+func commitBump(bump string, commit *Commit) string {
+	switch {
+	case commit.Conventional == nil:
+	case commit.Conventional.Breaking:
+		return BumpMajor
+	case commit.Conventional.Type == "feat" && bump != BumpMajor:
+		return BumpMinor
+	case (commit.Conventional.Type == "fix" || commit.Conventional.Type == "perf") && bump == BumpNone:
+		return BumpPatch
+	}
+
+	return bump
+}
+
+// NextVersion applies a bump to a semantic version of the form MAJOR.MINOR.PATCH,
+// optionally prefixed with "v".
+func NextVersion(version, bump string) (string, error) {
+	match := semver.FindStringSubmatch(version)
+	if match == nil {
+		return "", fmt.Errorf("Version `%s` is not of the form MAJOR.MINOR.PATCH", version)
+	}
+
+	parts := make([]int, 3)
+	for i := range parts {
+		parts[i], _ = strconv.Atoi(match[i+2])
+	}
+
+	switch bump {
+	case BumpMajor:
+		parts = []int{parts[0] + 1, 0, 0}
+	case BumpMinor:
+		parts = []int{parts[0], parts[1] + 1, 0}
+	case BumpPatch:
+		parts[2]++
+	case BumpNone:
+	default:
+		return "", fmt.Errorf("Unknown version bump `%s`", bump)
+	}
+
+	return fmt.Sprintf("%s%d.%d.%d", match[1], parts[0], parts[1], parts[2]), nil
+}
+
+// Changelog renders the relevant commits of a summary as a markdown changelog entry, grouped by
+// conventional commit type. The entry is headed by the next version if the current version is
+// given, and otherwise by "Unreleased".
+func Changelog(summary *Summary, version, commitURL string) ([]byte, error) {
+	heading := "Unreleased"
+	if len(version) > 0 {
+		next, err := NextVersion(version, summary.Bump)
+		if err != nil {
+			return nil, err
+		}
+		heading = next
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "## %s (%s)\n", heading, summary.Bump)
+	writeTypeSections(&buf, "###", summary.Commits, commitURL)
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alecholmes/tdiff/lib"
+)
+
+func TestSemverBump(t *testing.T) {
+	commit := func(commitType string, breaking bool) *Commit {
+		return &Commit{Conventional: &lib.ConventionalCommit{Type: commitType, Breaking: breaking}}
+	}
+	plain := &Commit{Description: "Fix the thing"}
+
+	testCases := []struct {
+		name     string
+		commits  []*Commit
+		expected string
+	}{
+		{name: "no commits", commits: nil, expected: BumpNone},
+		{name: "no conventional commits", commits: []*Commit{plain, plain}, expected: BumpNone},
+		{name: "docs and chores", commits: []*Commit{commit("docs", false), commit("chore", false)}, expected: BumpNone},
+		{name: "fix", commits: []*Commit{plain, commit("fix", false)}, expected: BumpPatch},
+		{name: "perf", commits: []*Commit{commit("perf", false)}, expected: BumpPatch},
+		{name: "feat over fix", commits: []*Commit{commit("fix", false), commit("feat", false), commit("fix", false)}, expected: BumpMinor},
+		{name: "breaking over feat", commits: []*Commit{commit("feat", false), commit("fix", true), commit("feat", false)}, expected: BumpMajor},
+		{name: "breaking chore", commits: []*Commit{commit("chore", true)}, expected: BumpMajor},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("case=%s", tc.name), func(t *testing.T) {
+			if actual := SemverBump(tc.commits); actual != tc.expected {
+				t.Fatalf("Expected %s but got %s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	testCases := []struct {
+		version  string
+		bump     string
+		expected string
+		err      bool
+	}{
+		{version: "1.2.3", bump: BumpMajor, expected: "2.0.0"},
+		{version: "1.2.3", bump: BumpMinor, expected: "1.3.0"},
+		{version: "1.2.3", bump: BumpPatch, expected: "1.2.4"},
+		{version: "1.2.3", bump: BumpNone, expected: "1.2.3"},
+		{version: "v0.9.9", bump: BumpMinor, expected: "v0.10.0"},
+		{version: "v1.2.3", bump: BumpMajor, expected: "v2.0.0"},
+		{version: "v1.2.3-rc.1", bump: BumpPatch, err: true},
+		{version: "v1.2.3+build", bump: BumpPatch, err: true},
+		{version: "1.2", bump: BumpPatch, err: true},
+		{version: "V1.2.3", bump: BumpPatch, err: true},
+		{version: "latest", bump: BumpPatch, err: true},
+		{version: "1.2.3", bump: "huge", err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("version=%s,bump=%s", tc.version, tc.bump), func(t *testing.T) {
+			actual, err := NextVersion(tc.version, tc.bump)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("Expected an error but got %s", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != tc.expected {
+				t.Fatalf("Expected %s but got %s", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/alecholmes/tdiff/lib"
+	"github.com/alecholmes/tdiff/source"
+)
+
+// classifyChanges classifies every changed file in relevant packages, and every changed artifact,
+// between the given SHA and HEAD. Each package is classified by its most significant file change.
+// If codeOnly is true, non-code file changes are dropped, as are packages without code changes.
+func (d *diff) classifyChanges(codeOnly bool, logger Logger) error {
+	d.codeOnly = codeOnly
+	d.summary.FileClassifications = make(map[string]string)
+	d.packageClassifications = make(map[string]string)
+
+	for _, file := range d.changedArtifactFiles {
+		d.summary.FileClassifications[file] = source.ChangeCode
+	}
+
+	for _, pkg := range d.relevantPackages.Slice() {
+		var files, classifications []string
+		for _, file := range d.changedPackageFiles[pkg] {
+			classification, err := d.classifyFile(d.summary.SHA, "HEAD", file)
+			if err != nil {
+				return err
+			}
+
+			classifications = append(classifications, classification)
+			if !codeOnly || classification == source.ChangeCode {
+				d.summary.FileClassifications[file] = classification
+				files = append(files, file)
+			}
+		}
+		d.changedPackageFiles[pkg] = files
+		d.packageClassifications[pkg] = source.MostSignificantChange(classifications...)
+
+		if codeOnly && len(files) == 0 {
+			logger("No code changed in package: %s", pkg)
+			delete(d.relevantPackages, pkg)
+		}
+	}
+
+	return nil
+}
+
+// classifyCommit returns the most significant classification of the changes a commit
+// made to the given relevant files.
+func (d *diff) classifyCommit(sha string, commitFiles []string, relevantFiles lib.StringSet) (string, error) {
+	var classifications []string
+	for _, file := range commitFiles {
+		if !relevantFiles.Contains(file) {
+			continue
+		}
+
+		classification, err := d.classifyFile(fmt.Sprintf("%s^", sha), sha, file)
+		if err != nil {
+			return "", err
+		}
+		classifications = append(classifications, classification)
+	}
+
+	return source.MostSignificantChange(classifications...), nil
+}
+
+// classifyFile classifies the change made to a file between two SHAs.
+func (d *diff) classifyFile(fromSHA, toSHA, file string) (string, error) {
+	oldBody, err := d.git.FileAt(fromSHA, file)
+	if err != nil {
+		return "", err
+	}
+	newBody, err := d.git.FileAt(toSHA, file)
+	if err != nil {
+		return "", err
+	}
+
+	return source.ClassifyChange(file, oldBody, newBody), nil
+}
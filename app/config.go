@@ -0,0 +1,185 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/alecholmes/tdiff/lib"
+)
+
+// DefaultConfigFile is the config file used if it exists at the root of the Git repository.
+const DefaultConfigFile = ".tdiff.json"
+
+// Config is a repository level configuration of tdiff.
+type Config struct {
+	Dependencies []*DependencyRule `json:"dependencies"` // Non-Go file dependencies of packages
+	Exclude      []string          `json:"exclude"`      // Globs of files that are never relevant
+	Generators   []*GeneratorRule  `json:"generators"`   // Inputs of //go:generate generators
+	Risk         *RiskConfig       `json:"risk"`         // Risk scoring, if customized
+	Links        LinkConfig        `json:"links"`        // Templates of links in release notes
+	Issues       []*IssueRule      `json:"issues"`       // How issue references are found in commit messages; see DefaultIssueRules
+	AuthorTeams  map[string]string `json:"authorTeams"`  // Teams of commit authors, by email or name
+	Policies     []*Policy         `json:"policies"`     // Rules about which packages may import which
+	Layers       []*Layer          `json:"layers"`       // Architectural layers, ordered from the top
+	CycleDepth   int               `json:"cycleDepth"`   // If positive, cycles between directories up to this depth are found
+}
+
+// IssueRule declares how references to issues are found in commit messages, and how they are linked.
+type IssueRule struct {
+	Pattern string `json:"pattern"` // Regular expression matching a reference, e.g. "PROJ-\\d+"
+	URL     string `json:"url"`     // Link to the issue, expanded with the match as by regexp.Expand, e.g. ".../issues/$1"
+	Exclude string `json:"exclude"` // Regular expression of matches that are not references, if any, e.g. "^SHA-\\d+$"
+}
+
+// DefaultIssueRules find references like PROJ-1234 and #567 if the config has no issue rules.
+// Project keys have at least two letters, and common names of standards like UTF-8 and SHA-256 are not references.
+var DefaultIssueRules = []*IssueRule{
+	{
+		Pattern: `\b[A-Z][A-Z0-9]*[A-Z][A-Z0-9]*-\d+\b`,
+		Exclude: `^(AES|CP|CVE|CWE|ECMA|HTTP|IEC|IEEE|ISO|MD|PEP|RFC|SHA|TLS|UCS|UTF)-\d+$`,
+	},
+	{Pattern: `#\d+\b`},
+}
+
+// LinkConfig declares templates of links to the repository's web interface.
+type LinkConfig struct {
+	Commit string `json:"commit"` // Link to a commit, where {sha} is replaced with the commit SHA
+}
+
+// DependencyRule declares files that packages depend on outside of their own directories,
+// and files that are not relevant to them.
+//
+// Package patterns are import paths where "..." matches any string, as with the go tool.
+// Patterns starting with "./" are relative to the root of the Git repository.
+// File globs are relative to the root of the Git repository (see lib.MatchGlob).
+type DependencyRule struct {
+	Packages []string `json:"packages"` // Patterns of packages the rule applies to
+	Files    []string `json:"files"`    // Globs of files the packages depend on
+	Exclude  []string `json:"exclude"`  // Globs of files that are not relevant to the packages
+}
+
+// GeneratorRule declares the inputs of a generator run by //go:generate directives.
+// Packages with directives using the generator depend on the inputs.
+type GeneratorRule struct {
+	Tool   string   `json:"tool"`   // Command (e.g. "stringer") or package pattern run with "go run"
+	Inputs []string `json:"inputs"` // Globs of files the generated code depends on
+}
+
+// LoadConfig reads a JSON config file.
+func LoadConfig(file string) (*Config, error) {
+	body, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := json.Unmarshal(body, &config); err != nil {
+		return nil, fmt.Errorf("Unable to parse config file %s: %v", file, err)
+	}
+
+	return &config, nil
+}
+
+// loadRepoConfig loads the given config file or, if none is given, the default config
+// file at the root of the Git repository. An empty config is returned if neither exists.
+func loadRepoConfig(file, gitRoot string, logger Logger) (*Config, error) {
+	if len(file) == 0 {
+		file = filepath.Join(gitRoot, DefaultConfigFile)
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+	}
+
+	logger("Using config file: %s", file)
+	return LoadConfig(file)
+}
+
+// authorTeam returns the team of an author, looked up by email and then by name.
+// An empty string is returned if the author has no team.
+func (c *Config) authorTeam(name, email string) string {
+	if team, ok := c.AuthorTeams[email]; ok {
+		return team
+	}
+
+	return c.AuthorTeams[name]
+}
+
+// excludes returns true if the file is never relevant.
+func (c *Config) excludes(file string) bool {
+	return matchAnyGlob(c.Exclude, file)
+}
+
+// excludesPackageFile returns true if the file is not relevant to the given package.
+func (c *Config) excludesPackageFile(pkg, file, packagePrefix string) bool {
+	for _, rule := range c.Dependencies {
+		if rule.matchesPackage(pkg, packagePrefix) && matchAnyGlob(rule.Exclude, file) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// configDependents returns a fileDependents for files the config declares as dependencies of reachable packages.
+func configDependents(config *Config, reachablePackages []string, packagePrefix string) fileDependents {
+	return func(file string) []string {
+		var packages []string
+		for _, rule := range config.Dependencies {
+			if !matchAnyGlob(rule.Files, file) {
+				continue
+			}
+			for _, pkg := range reachablePackages {
+				if rule.matchesPackage(pkg, packagePrefix) {
+					packages = append(packages, pkg)
+				}
+			}
+		}
+
+		return packages
+	}
+}
+
+// matchesPackage returns true if the rule applies to the given package.
+func (r *DependencyRule) matchesPackage(importPath, packagePrefix string) bool {
+	for _, pattern := range r.Packages {
+		if matchPackagePattern(pattern, importPath, packagePrefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchPackagePattern returns true if the import path matches a package pattern.
+// matchPackagePattern("a/...", "a", "") == true
+// matchPackagePattern("a/...", "a/b/c", "") == true
+// matchPackagePattern("./b", "repo/b", "repo") == true
+func matchPackagePattern(pattern, importPath, packagePrefix string) bool {
+	if pattern == "." || strings.HasPrefix(pattern, "./") {
+		pattern = path.Join(packagePrefix, pattern)
+	}
+
+	if strings.HasSuffix(pattern, "/...") && importPath == strings.TrimSuffix(pattern, "/...") {
+		return true
+	}
+
+	expr := fmt.Sprintf("^%s$", strings.Replace(regexp.QuoteMeta(pattern), `\.\.\.`, `.*`, -1))
+	matched, err := regexp.MatchString(expr, importPath)
+	return err == nil && matched
+}
+
+func matchAnyGlob(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if lib.MatchGlob(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tdiff-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	noLogger := func(string, ...interface{}) {}
+
+	// Without a config file, the config is empty.
+	config, err := loadRepoConfig("", dir, noLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&Config{}, config) {
+		t.Fatalf("Expected empty config but got %+v", config)
+	}
+
+	body := `{"dependencies": [{"packages": ["./svc/..."], "files": ["schema/*.sql"]}], "exclude": ["*.md"], "cycleDepth": 2}`
+	if err := ioutil.WriteFile(filepath.Join(dir, DefaultConfigFile), []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expected := &Config{
+		Dependencies: []*DependencyRule{{Packages: []string{"./svc/..."}, Files: []string{"schema/*.sql"}}},
+		Exclude:      []string{"*.md"},
+		CycleDepth:   2,
+	}
+	if config, err = loadRepoConfig("", dir, noLogger); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, config) {
+		t.Fatalf("Expected %+v but got %+v", expected, config)
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := ioutil.WriteFile(invalid, []byte(`{"exclude": "*.md"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadRepoConfig(invalid, dir, noLogger); err == nil {
+		t.Fatal("Expected an error for an invalid config file")
+	}
+	if _, err := loadRepoConfig(filepath.Join(dir, "missing.json"), dir, noLogger); err == nil {
+		t.Fatal("Expected an error for a missing config file")
+	}
+}
+
+func TestMatchPackagePattern(t *testing.T) {
+	testCases := []struct {
+		pattern    string
+		importPath string
+		expected   bool
+	}{
+		{pattern: "repo/a", importPath: "repo/a", expected: true},
+		{pattern: "repo/a", importPath: "repo/a/b", expected: false},
+		{pattern: "repo/a/...", importPath: "repo/a", expected: true},
+		{pattern: "repo/a/...", importPath: "repo/a/b/c", expected: true},
+		{pattern: "repo/a/...", importPath: "repo/ab", expected: false},
+		{pattern: "repo/.../c", importPath: "repo/a/b/c", expected: true},
+		{pattern: "./...", importPath: "repo", expected: true},
+		{pattern: "./...", importPath: "repo/a/b", expected: true},
+		{pattern: "./...", importPath: "other/a", expected: false},
+		{pattern: ".", importPath: "repo", expected: true},
+		{pattern: "./a", importPath: "repo/a", expected: true},
+		{pattern: "./a", importPath: "a", expected: false},
+		{pattern: "a", importPath: "repo/a", expected: false},
+		{pattern: "./a/...", importPath: "repo/a/b", expected: true},
+		{pattern: "repo/a.b", importPath: "repo/aXb", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("pattern=%s,importPath=%s", tc.pattern, tc.importPath), func(t *testing.T) {
+			if actual := matchPackagePattern(tc.pattern, tc.importPath, "repo"); actual != tc.expected {
+				t.Fatalf("Expected %v but got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestConfigDependents(t *testing.T) {
+	config := &Config{
+		Dependencies: []*DependencyRule{
+			{Packages: []string{"./svc/..."}, Files: []string{"schema/*.sql"}},
+			{Packages: []string{"repo/lib"}, Files: []string{"schema/users.sql", "assets/**"}},
+		},
+	}
+	reachable := []string{"repo/cmd", "repo/lib", "repo/svc", "repo/svc/api"}
+	dependents := configDependents(config, reachable, "repo")
+
+	testCases := []struct {
+		file     string
+		expected []string
+	}{
+		{file: "schema/orders.sql", expected: []string{"repo/svc", "repo/svc/api"}},
+		{file: "schema/users.sql", expected: []string{"repo/svc", "repo/svc/api", "repo/lib"}},
+		{file: "assets/img/logo.png", expected: []string{"repo/lib"}},
+		{file: "schema/nested/orders.sql", expected: nil},
+		{file: "README.md", expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("file=%s", tc.file), func(t *testing.T) {
+			if actual := dependents(tc.file); !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("Expected %v but got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAuthorTeam(t *testing.T) {
+	config := &Config{AuthorTeams: map[string]string{
+		"alice@example.com": "payments",
+		"Alice":             "search",
+		"Bob":               "infra",
+	}}
+
+	testCases := []struct {
+		name     string
+		email    string
+		expected string
+	}{
+		{name: "Alice", email: "alice@example.com", expected: "payments"},
+		{name: "Alice", email: "alice@other.com", expected: "search"},
+		{name: "Bob", email: "bob@example.com", expected: "infra"},
+		{name: "Carol", email: "carol@example.com", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("name=%s,email=%s", tc.name, tc.email), func(t *testing.T) {
+			if actual := config.authorTeam(tc.name, tc.email); actual != tc.expected {
+				t.Fatalf("Expected %q but got %q", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"sort"
+
+	"github.com/alecholmes/tdiff/lib"
+)
+
+// Contributor describes the lines an author last changed in the relevant files.
+type Contributor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Team  string `json:"team,omitempty"` // Team of the author in the config, if any
+	Lines int    `json:"lines"`          // Lines of relevant files last changed by the author in range
+	Files int    `json:"files"`          // Relevant files with lines last changed by the author in range
+}
+
+// Team describes the lines a team's authors last changed in the relevant files.
+type Team struct {
+	Name         string   `json:"name"`
+	Lines        int      `json:"lines"`        // Lines of relevant files last changed by the team in range
+	Contributors []string `json:"contributors"` // Emails of the team's contributors
+}
+
+// determineContributors blames the relevant files to find who authored the lines changed in range,
+// and aggregates them by team.
+func (d *diff) determineContributors() error {
+	fileAuthors := make([][]lib.GitAuthorLines, 0, len(d.summary.Files))
+	for _, file := range d.summary.Files {
+		authors, err := d.git.BlameRange(d.summary.SHA, "HEAD", file)
+		if err != nil {
+			return err
+		}
+		fileAuthors = append(fileAuthors, authors)
+	}
+
+	d.summary.Contributors, d.summary.Teams = aggregateContributors(fileAuthors, d.config)
+
+	return nil
+}
+
+// aggregateContributors aggregates the authors of lines of each file into contributors, ordered by lines,
+// descending, and then by email, and the teams of contributors in the config, ordered by lines, descending,
+// and then by name.
+func aggregateContributors(fileAuthors [][]lib.GitAuthorLines, config *Config) ([]*Contributor, []*Team) {
+	contributors := make(map[string]*Contributor)
+	for _, authors := range fileAuthors {
+		for _, author := range authors {
+			contributor, ok := contributors[author.Email]
+			if !ok {
+				contributor = &Contributor{Name: author.Name, Email: author.Email, Team: config.authorTeam(author.Name, author.Email)}
+				contributors[author.Email] = contributor
+			}
+			contributor.Lines += author.Lines
+			contributor.Files++
+		}
+	}
+
+	var contributorList []*Contributor
+	var teamList []*Team
+	teams := make(map[string]*Team)
+	for _, contributor := range contributors {
+		contributorList = append(contributorList, contributor)
+
+		if len(contributor.Team) == 0 {
+			continue
+		}
+		team, ok := teams[contributor.Team]
+		if !ok {
+			team = &Team{Name: contributor.Team}
+			teams[contributor.Team] = team
+			teamList = append(teamList, team)
+		}
+		team.Lines += contributor.Lines
+		team.Contributors = append(team.Contributors, contributor.Email)
+	}
+
+	sort.Slice(contributorList, func(i, j int) bool {
+		a, b := contributorList[i], contributorList[j]
+		if a.Lines != b.Lines {
+			return a.Lines > b.Lines
+		}
+		return a.Email < b.Email
+	})
+	for _, team := range teamList {
+		sort.Strings(team.Contributors)
+	}
+	sort.Slice(teamList, func(i, j int) bool {
+		a, b := teamList[i], teamList[j]
+		if a.Lines != b.Lines {
+			return a.Lines > b.Lines
+		}
+		return a.Name < b.Name
+	})
+
+	return contributorList, teamList
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alecholmes/tdiff/lib"
+)
+
+func TestAggregateContributors(t *testing.T) {
+	config := &Config{AuthorTeams: map[string]string{
+		"alice@example.com": "payments",
+		"Bob":               "payments",
+		"carol@example.com": "search",
+	}}
+	fileAuthors := [][]lib.GitAuthorLines{
+		{
+			{Name: "Alice", Email: "alice@example.com", Lines: 10},
+			{Name: "Bob", Email: "bob@example.com", Lines: 2},
+		},
+		nil,
+		{
+			{Name: "Carol", Email: "carol@example.com", Lines: 12},
+			{Name: "Alice", Email: "alice@example.com", Lines: 2},
+			{Name: "Dave", Email: "dave@example.com", Lines: 2},
+		},
+	}
+
+	expectedContributors := []*Contributor{
+		{Name: "Alice", Email: "alice@example.com", Team: "payments", Lines: 12, Files: 2},
+		{Name: "Carol", Email: "carol@example.com", Team: "search", Lines: 12, Files: 1},
+		{Name: "Bob", Email: "bob@example.com", Team: "payments", Lines: 2, Files: 1},
+		{Name: "Dave", Email: "dave@example.com", Lines: 2, Files: 1},
+	}
+	expectedTeams := []*Team{
+		{Name: "payments", Lines: 14, Contributors: []string{"alice@example.com", "bob@example.com"}},
+		{Name: "search", Lines: 12, Contributors: []string{"carol@example.com"}},
+	}
+
+	contributors, teams := aggregateContributors(fileAuthors, config)
+	if !reflect.DeepEqual(expectedContributors, contributors) {
+		t.Fatalf("Expected contributors %v but got %v", expectedContributors, contributors)
+	}
+	if !reflect.DeepEqual(expectedTeams, teams) {
+		t.Fatalf("Expected teams %v but got %v", expectedTeams, teams)
+	}
+}
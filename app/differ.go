@@ -9,6 +9,7 @@ import (
 
 	"github.com/alecholmes/tdiff/importer"
 	"github.com/alecholmes/tdiff/lib"
+	"github.com/alecholmes/tdiff/source"
 )
 
 type Logger func(string, ...interface{})
@@ -16,22 +17,82 @@ type Logger func(string, ...interface{})
 func NoLogging(string, ...interface{}) {}
 
 type Package struct {
-	ImportPath   string   `json:"name"`
-	PathFromRoot []string `json:"pathFromRoot"`
+	ImportPath     string              `json:"name"` // Also encoded as importPath (see MarshalJSON)
+	PathFromRoot   []string            `json:"pathFromRoot"`
+	Paths          [][]string          `json:"paths,omitempty"`          // Import paths from the root package, if enumerated.
+	Importers      []string            `json:"importers,omitempty"`      // Packages reachable from the root that import the package, if paths are enumerated.
+	Files          []string            `json:"files"`                    // Relevant changed files of the package
+	ChangedSymbols []string            `json:"changedSymbols,omitempty"` // Changed declarations used by the root package, if symbols were analyzed.
+	APIChanges     []*source.APIChange `json:"apiChanges,omitempty"`     // Changes to the exported API, if compared.
+	Classification string              `json:"classification,omitempty"` // Most significant change to the package, if classified.
+	Impact         *Impact             `json:"impact,omitempty"`         // How much of the root's graph the package affects, if computed.
+	Owners         []string            `json:"owners,omitempty"`         // Owners of the relevant changed files, if requested.
 }
 
 type Commit struct {
-	SHA              string     `json:"sha"`
-	Description      string     `json:"description"`
-	RelevantPackages []*Package `json:"relevantPackages"` // This may be empty if only artifacts in non-Go subdirectories changed.
+	SHA              string                  `json:"sha"`
+	Description      string                  `json:"description"`
+	Conventional     *lib.ConventionalCommit `json:"conventional,omitempty"`   // Parsed description, if it follows Conventional Commits.
+	RelevantPackages []*Package              `json:"relevantPackages"`         // This may be empty if only artifacts in non-Go subdirectories changed.
+	Classification   string                  `json:"classification,omitempty"` // Most significant change to relevant files, if classified.
+	Files            []string                `json:"files"`                    // Relevant files changed by the commit
+	Patch            string                  `json:"patch,omitempty"`          // Patch of the relevant files, if requested.
+	Risk             *Risk                   `json:"risk,omitempty"`           // Risk score of the relevant changes, if scored.
+	Issues           []string                `json:"issues,omitempty"`         // Issues referenced by the commit message
 }
 
 type Summary struct {
+	SchemaVersion  int        `json:"schemaVersion"` // See SchemaVersion
 	RootImportPath string     `json:"rootImportPath"`
 	SHA            string     `json:"sha"`
 	Packages       []*Package `json:"packages"`
 	Commits        []*Commit  `json:"commits"`
+	OtherCommits   []*Commit  `json:"otherCommits,omitempty"` // Commits in range that are not relevant, if requested.
+	Bump           string     `json:"bump"`                   // Semantic version bump suggested by relevant commits (see SemverBump)
+	Issues         []*Issue   `json:"issues,omitempty"`       // Issues referenced by relevant commits
 	Files          []string   `json:"files"`
+
+	FileClassifications map[string]string   `json:"fileClassifications,omitempty"` // Change classification by file, if classified.
+	FileOwners          map[string][]string `json:"fileOwners,omitempty"`          // Owners by file, if requested.
+	Owners              []*Owner            `json:"owners,omitempty"`              // Relevant changes by owner, if requested.
+	Contributors        []*Contributor      `json:"contributors,omitempty"`        // Authors of the relevant changed lines, if blamed.
+	Teams               []*Team             `json:"teams,omitempty"`               // Teams of the authors of the relevant changed lines, if blamed.
+	Risk                *Risk               `json:"risk,omitempty"`                // Risk score of all relevant changes, if scored.
+	Violations          []*PolicyViolation  `json:"violations,omitempty"`          // Policy violations introduced by the changes, if checked.
+	LayerViolations     []*LayerViolation   `json:"layerViolations,omitempty"`     // Layer violations introduced by the changes, if checked.
+	Cycles              []*DirectoryCycle   `json:"cycles,omitempty"`              // Directory cycles introduced by the changes, if checked.
+	Findings            []*Finding          `json:"findings,omitempty"`            // Findings about relevant changes, if requested.
+
+	Graph  *importer.PackageGraph `json:"-"` // Graph of all packages reachable from the root package
+	Config *Config                `json:"-"` // Config of the Git repository
+}
+
+// Options control which changes Differ.Diff considers relevant.
+type Options struct {
+	Artifacts bool // Include changed non-Go files nested under reachable package directories.
+	Symbols   bool // Only consider Go changes that touch declarations used by the root package.
+	API       bool // Compare the exported API of relevant packages.
+	Classify  bool // Classify changes as formatting, comment, test or code changes.
+	CodeOnly  bool // Only consider code changes relevant. Implies Classify.
+	Generate  bool // Treat //go:generate generators and their inputs as dependencies.
+	Patches   bool // Include the patch of relevant files in each commit.
+	Other     bool // Include commits in range that are not relevant.
+	Owners    bool // Annotate relevant files and packages with their owners from the repository's CODEOWNERS file.
+	Blame     bool // Blame relevant files to find the authors and teams of the lines changed in range.
+	Policies  bool // Check the config's policies and layers, and directory cycles, for violations introduced by the changes.
+	Findings  bool // Collect findings about relevant changes; breaking API changes and policy, layer and cycle violations are included if checked.
+
+	Impact bool   // Compute the impact of each relevant package on the root's graph.
+	SortBy string // Order of relevant packages, one of the SortBy constants. Orderings other than by name imply Impact.
+
+	Risk          bool    // Score the risk of the relevant changes and of each relevant commit.
+	RiskThreshold float64 // If positive, overrides the config's risk threshold. Implies Risk.
+
+	Paths         bool // Include the import paths from the root to each relevant package, and its direct importers.
+	MaxPaths      int  // If positive, at most this many import paths are included per package.
+	MaxPathLength int  // If positive, included import paths have at most this many packages.
+
+	ConfigFile string // Config file to use instead of the default config file in the Git repository, if any.
 }
 
 type Differ struct {
@@ -52,25 +113,118 @@ func NewDiffer(goPath string, importer func(string) (*importer.PackageGraph, err
 	}
 }
 
-func (d *Differ) Diff(importPath, sha string, artifacts bool) (*Summary, error) {
+func (d *Differ) Diff(importPath, sha string, opts Options) (*Summary, error) {
+	return d.DiffEvents(importPath, sha, opts, nil)
+}
+
+// DiffEvents is like Diff, but also calls emit with each part of the summary as soon as it is
+// determined (see Event), so that callers can stream results for large ranges of commits.
+// If emit returns an error, the diff stops and returns it.
+//
+// If emit is set, relevant commits are not retained once emitted, so that memory use does not grow with
+// the range of commits: the returned summary has no commits, but has the bump, issues and risk aggregated
+// over them. Events share parts with the returned summary and must not be modified.
+func (d *Differ) DiffEvents(importPath, sha string, opts Options, emit func(*Event) error) (*Summary, error) {
 	diff := diff{
+		emitEvent: emit,
 		summary: Summary{
+			SchemaVersion:  SchemaVersion,
 			RootImportPath: importPath,
 			SHA:            sha,
+			Packages:       []*Package{},
+			Commits:        []*Commit{},
+			Bump:           BumpNone,
 		},
 	}
 
-	if err := diff.determineRelevantPackages(d.goPath, artifacts, d.logger); err != nil {
+	if err := diff.emit(&Event{Type: EventStart, SchemaVersion: SchemaVersion, RootImportPath: importPath, SHA: sha}); err != nil {
 		return nil, err
 	}
 
+	if err := diff.determineRelevantPackages(d.goPath, opts, d.logger); err != nil {
+		return nil, err
+	}
+
+	if opts.Symbols {
+		if err := diff.filterBySymbols(d.logger); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Classify || opts.CodeOnly {
+		if err := diff.classifyChanges(opts.CodeOnly, d.logger); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := diff.createPackageSummaries(d.includePaths); err != nil {
 		return nil, err
 	}
 
+	if opts.Impact || (len(opts.SortBy) > 0 && opts.SortBy != SortByName) {
+		if err := diff.determineImpact(); err != nil {
+			return nil, err
+		}
+		if err := diff.sortPackages(opts.SortBy); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Paths {
+		if err := diff.determinePaths(opts.MaxPaths, opts.MaxPathLength); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.API {
+		if err := diff.determineAPIChanges(); err != nil {
+			return nil, err
+		}
+	}
+
 	diff.determineRelevantFiles()
 
-	if err := diff.determineCommits(); err != nil {
+	if opts.Owners {
+		if err := diff.determineOwners(d.logger); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := diff.emitPackagesAndFiles(); err != nil {
+		return nil, err
+	}
+
+	if opts.Blame {
+		if err := diff.determineContributors(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Risk is scored first, so that the risk of each commit is scored as it is determined.
+	if opts.Risk || opts.RiskThreshold > 0 {
+		if err := diff.determineRisk(opts.RiskThreshold); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := diff.determineCommits(opts.Patches, opts.Other); err != nil {
+		return nil, err
+	}
+	diff.determineIssues()
+
+	if opts.Policies {
+		if err := diff.checkPolicies(); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Findings {
+		if err := diff.determineFindings(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := diff.emit(endEvent(&diff.summary)); err != nil {
 		return nil, err
 	}
 
@@ -78,30 +232,59 @@ func (d *Differ) Diff(importPath, sha string, artifacts bool) (*Summary, error)
 }
 
 type diff struct {
-	summary Summary
+	summary   Summary
+	emitEvent func(*Event) error // Called with parts of the summary as they are determined, if set
 
 	git                  *lib.Git
+	packagePrefix        string // Import path of the root of the Git repository
+	config               *Config
 	graph                *importer.PackageGraph
 	relevantPackages     lib.StringSet       // Relevant packages that changed
 	packageSummaries     map[string]*Package // Summaries by package import path
 	changedArtifactFiles []string            // Artifacts that changed
 	changedPackageFiles  map[string][]string // Files that changed by package
-	changedFilePackage   map[string]string   // Package name by file
+	changedFilePackages  map[string][]string // Package names by file
+	usedSymbols          map[string]bool     // Declarations used by the root, if symbols are analyzed
+	changedSymbols       map[string][]string // Changed declarations used by the root, by package
+
+	issueMatchers []*issueMatcher          // Rules for finding issue references in commit messages
+	issueURLs     map[string]string        // Links to referenced issues, by ID
+	issues        map[string]*Issue        // Issues referenced by relevant commits, by ID
+	issuePackages map[string]lib.StringSet // Relevant packages changed by commits referencing each issue, by ID
+
+	riskWeights map[string]float64 // Weight of each risk signal, if risk is scored
+	riskFanIns  map[string]int     // Fan-in of each relevant package, if risk is scored
+
+	packageClassifications map[string]string // Most significant change by package, if classified
+	codeOnly               bool              // Whether only code changes are relevant
+	generate               bool              // Whether packages run by //go:generate directives are imports
 }
 
-func (d *diff) determineRelevantPackages(goPath string, artifacts bool, logger Logger) error {
+func (d *diff) determineRelevantPackages(goPath string, opts Options, logger Logger) error {
 	packageNamer, git, err := newGitPackageNamer(d.summary.RootImportPath, goPath, logger)
 	d.git = git
 	if err != nil {
 		return err
 	}
+	d.packagePrefix = packageNamer("")
+
+	if d.config, err = loadRepoConfig(opts.ConfigFile, git.RootDir, logger); err != nil {
+		return err
+	}
+	d.summary.Config = d.config
+	if d.issueMatchers, err = newIssueMatchers(d.config); err != nil {
+		return err
+	}
+	d.issueURLs = make(map[string]string)
 
 	// Find all packages recursively reachable from the given root package.
-	reachablePackages, packageGraph, err := recursiveDeps(d.summary.RootImportPath)
+	d.generate = opts.Generate
+	reachablePackages, packageGraph, err := recursiveDeps(d.summary.RootImportPath, opts.Generate)
 	if err != nil {
 		return err
 	}
 	d.graph = packageGraph
+	d.summary.Graph = packageGraph
 
 	// Add given root package to the reachable set
 	reachablePackages = append(reachablePackages, d.summary.RootImportPath)
@@ -111,20 +294,50 @@ func (d *diff) determineRelevantPackages(goPath string, artifacts bool, logger L
 
 	// Find all files that changed since the given SHA.
 	// Not all files will be relevant, as some will be in unreachable packages.
-	files, err := git.DiffFiles(d.summary.SHA, "HEAD")
+	allFiles, err := git.DiffFiles(d.summary.SHA, "HEAD")
 	if err != nil {
 		return err
 	}
+	var files []string
+	for _, file := range allFiles {
+		if !d.config.excludes(file) {
+			files = append(files, file)
+		}
+	}
 
 	// Determine all the packages with changes.
 	d.changedPackageFiles = make(map[string][]string)
-	d.changedFilePackage = make(map[string]string)
+	d.changedFilePackages = make(map[string][]string)
+	protos, err := protoDependents(d.graph, git)
+	if err != nil {
+		return err
+	}
+	dependents := []fileDependents{
+		embedDependents(d.graph, git.RootDir),
+		cgoDependents(d.graph, git.RootDir),
+		protos,
+		configDependents(d.config, reachablePackages, d.packagePrefix),
+	}
+	if opts.Generate {
+		generated, err := generateDependents(d.graph, d.config, git.RootDir, d.packagePrefix)
+		if err != nil {
+			return err
+		}
+		dependents = append(dependents, generated)
+	}
 	for _, file := range files {
 		// For non-Go source files, some derived package names might not be actual Go packages.
 		packageName := packageNamer(filepath.Dir(file))
 		if reachablePackageSet.Contains(packageName) {
-			d.changedPackageFiles[packageName] = append(d.changedPackageFiles[packageName], file)
-			d.changedFilePackage[file] = packageName
+			d.addChangedPackageFile(packageName, file)
+		}
+
+		// Some files are inputs to packages outside of their own directory, such as embedded files,
+		// C headers, protobuf sources, generator inputs and dependencies declared in the config.
+		for _, dependent := range dependents {
+			for _, pkg := range dependent(file) {
+				d.addChangedPackageFile(pkg, file)
+			}
 		}
 	}
 
@@ -138,14 +351,14 @@ func (d *diff) determineRelevantPackages(goPath string, artifacts bool, logger L
 	}
 
 	// Add any artifact files that changed
-	if artifacts {
+	if opts.Artifacts {
 		for _, file := range files {
 			if !strings.HasSuffix(file, ".go") {
 				packageName := packageNamer(filepath.Dir(file))
 
 				// Not the most efficient way of doing this...
 				for _, reachablePackage := range reachablePackages {
-					if importPathNestedWithin(packageName, reachablePackage) {
+					if importPathNestedWithin(packageName, reachablePackage) && !d.config.excludesPackageFile(reachablePackage, file, d.packagePrefix) {
 						d.changedArtifactFiles = append(d.changedArtifactFiles, file)
 
 						// Artifact files don't necessarily live in a real Go package
@@ -161,13 +374,106 @@ func (d *diff) determineRelevantPackages(goPath string, artifacts bool, logger L
 	return nil
 }
 
+// filterBySymbols removes relevant packages whose Go source changes do not touch any
+// declaration transitively used by the root package.
+func (d *diff) filterBySymbols(logger Logger) error {
+	used, err := d.graph.UsedSymbols(d.summary.RootImportPath, logger)
+	if err != nil {
+		return err
+	}
+	d.usedSymbols = used
+
+	d.changedSymbols = make(map[string][]string)
+	for _, pkg := range d.relevantPackages.Slice() {
+		changedSymbols, relevant, err := d.changedUsedSymbols(pkg, d.summary.SHA, "HEAD", d.changedPackageFiles[pkg])
+		if err != nil {
+			return err
+		}
+		d.changedSymbols[pkg] = changedSymbols
+
+		if !relevant {
+			logger("No used symbols changed in package: %s", pkg)
+			delete(d.relevantPackages, pkg)
+		}
+	}
+
+	return nil
+}
+
+// changedUsedSymbols returns the changed declarations used by the root package in
+// the given files of a package between two SHAs, and whether the changes are relevant.
+// Changes to the root package, and changes to non-Go files, are always relevant.
+// Changes to test files outside of the root package are never used by it.
+func (d *diff) changedUsedSymbols(pkg, fromSHA, toSHA string, files []string) ([]string, bool, error) {
+	relevant := pkg == d.summary.RootImportPath
+	oldFiles := make(map[string][]byte)
+	newFiles := make(map[string][]byte)
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".go") {
+			relevant = true
+			continue
+		} else if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		var err error
+		if oldFiles[file], err = d.git.FileAt(fromSHA, file); err != nil {
+			return nil, false, err
+		}
+		if newFiles[file], err = d.git.FileAt(toSHA, file); err != nil {
+			return nil, false, err
+		}
+	}
+
+	oldDecls, err := source.ParseDecls(pkg, oldFiles)
+	if err != nil {
+		return nil, false, err
+	}
+	newDecls, err := source.ParseDecls(pkg, newFiles)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var changedSymbols []string
+	for _, key := range source.ChangedDecls(oldDecls, newDecls) {
+		if d.usedSymbols[key] {
+			changedSymbols = append(changedSymbols, key)
+		}
+	}
+
+	return changedSymbols, relevant || len(changedSymbols) > 0, nil
+}
+
+// addChangedPackageFile records a changed file as belonging to a package.
+// Files the config excludes from the package are ignored.
+func (d *diff) addChangedPackageFile(pkg, file string) {
+	if d.config.excludesPackageFile(pkg, file, d.packagePrefix) {
+		return
+	}
+	for _, existing := range d.changedFilePackages[file] {
+		if existing == pkg {
+			return
+		}
+	}
+
+	d.changedPackageFiles[pkg] = append(d.changedPackageFiles[pkg], file)
+	d.changedFilePackages[file] = append(d.changedFilePackages[file], pkg)
+}
+
 func (d *diff) createPackageSummaries(includePaths bool) error {
 	d.packageSummaries = make(map[string]*Package)
 	outPackages := d.relevantPackages.Slice()
 	sort.Strings(outPackages)
 
 	for _, pkg := range outPackages {
-		packageSummary := &Package{ImportPath: pkg}
+		files := append([]string{}, d.changedPackageFiles[pkg]...)
+		sort.Strings(files)
+		packageSummary := &Package{
+			ImportPath:     pkg,
+			Files:          files,
+			ChangedSymbols: d.changedSymbols[pkg],
+			Classification: d.packageClassifications[pkg],
+		}
 		d.summary.Packages = append(d.summary.Packages, packageSummary)
 		d.packageSummaries[pkg] = packageSummary
 
@@ -186,6 +492,25 @@ func (d *diff) createPackageSummaries(includePaths bool) error {
 	return nil
 }
 
+// determinePaths enumerates the import paths from the root to each relevant package, and its direct importers.
+func (d *diff) determinePaths(maxPaths, maxLength int) error {
+	for _, pkg := range d.summary.Packages {
+		paths, err := d.graph.AllPaths(d.summary.RootImportPath, pkg.ImportPath, maxPaths, maxLength)
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			pkg.Paths = append(pkg.Paths, path)
+		}
+
+		if pkg.Importers, err = d.graph.Importers(pkg.ImportPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (d *diff) determineRelevantFiles() {
 	outFileSet := make(lib.StringSet)
 	outFileSet.Add(d.changedArtifactFiles...)
@@ -198,7 +523,7 @@ func (d *diff) determineRelevantFiles() {
 	d.summary.Files = outFiles
 }
 
-func (d *diff) determineCommits() error {
+func (d *diff) determineCommits(patches, other bool) error {
 	commits, err := d.git.Commits(d.summary.SHA, "HEAD")
 	if err != nil {
 		log.Fatal(err)
@@ -211,7 +536,6 @@ func (d *diff) determineCommits() error {
 	}
 	relevantFiles.Add(d.changedArtifactFiles...)
 
-	var relevantCommits []lib.GitCommit
 	for _, commit := range commits {
 		commitFiles, err := d.git.CommitFiles(commit.SHA)
 		if err != nil {
@@ -226,14 +550,29 @@ func (d *diff) determineCommits() error {
 				break
 			}
 		}
-		if relevant {
-			relevantCommits = append(relevantCommits, commit)
 
-			commitPackageSet := make(lib.StringSet)
-			for _, file := range commitFiles {
-				commitPackageSet.Add(d.changedFilePackage[file])
+		commitPackageSet := make(lib.StringSet)
+		for _, file := range commitFiles {
+			commitPackageSet.Add(d.changedFilePackages[file]...)
+		}
+
+		// When analyzing symbols, the commit must also change a used declaration.
+		if relevant && d.usedSymbols != nil {
+			if commitPackageSet, err = d.commitSymbolPackages(commit.SHA, commitFiles, commitPackageSet); err != nil {
+				return err
 			}
+			relevant = len(commitPackageSet) > 0 || d.commitChangesArtifacts(commitFiles)
+		}
 
+		var classification string
+		if relevant && d.packageClassifications != nil {
+			if classification, err = d.classifyCommit(commit.SHA, commitFiles, relevantFiles); err != nil {
+				return err
+			}
+			relevant = !d.codeOnly || classification == source.ChangeCode
+		}
+
+		if relevant {
 			commitPackages := commitPackageSet.Slice()
 			sort.Strings(commitPackages)
 			commitPackageSummaries := []*Package{}
@@ -243,18 +582,99 @@ func (d *diff) determineCommits() error {
 				}
 			}
 
-			d.summary.Commits = append(d.summary.Commits, &Commit{
+			files := []string{}
+			for _, file := range commitFiles {
+				if relevantFiles.Contains(file) {
+					files = append(files, file)
+				}
+			}
+			sort.Strings(files)
+
+			var patch string
+			if patches {
+				if patch, err = d.git.CommitPatch(commit.SHA, files...); err != nil {
+					return err
+				}
+			}
+
+			relevantCommit := &Commit{
 				SHA:              commit.SHA,
 				Description:      commit.Description,
+				Conventional:     commit.Conventional(),
 				RelevantPackages: commitPackageSummaries,
-			})
+				Classification:   classification,
+				Files:            files,
+				Patch:            patch,
+				Issues:           d.findIssues(commit.Description + "\n" + commit.Body),
+			}
+			if err := d.scoreCommitRisk(relevantCommit); err != nil {
+				return err
+			}
+			d.summary.Bump = commitBump(d.summary.Bump, relevantCommit)
+			d.addCommitIssues(relevantCommit)
 
+			// When streaming, commits are not retained once emitted.
+			if d.emitEvent != nil {
+				if err := d.emit(&Event{Type: EventCommit, Commit: relevantCommit}); err != nil {
+					return err
+				}
+			} else {
+				d.summary.Commits = append(d.summary.Commits, relevantCommit)
+			}
+
+		} else if other {
+			d.summary.OtherCommits = append(d.summary.OtherCommits, &Commit{
+				SHA:              commit.SHA,
+				Description:      commit.Description,
+				Conventional:     commit.Conventional(),
+				RelevantPackages: []*Package{},
+				Files:            []string{},
+			})
 		}
 	}
 
 	return nil
 }
 
+// commitSymbolPackages returns the subset of the given relevant packages in which
+// a commit changed declarations used by the root package.
+func (d *diff) commitSymbolPackages(sha string, commitFiles []string, packages lib.StringSet) (lib.StringSet, error) {
+	filesByPackage := make(map[string][]string)
+	for _, file := range commitFiles {
+		for _, pkg := range d.changedFilePackages[file] {
+			if d.relevantPackages.Contains(pkg) {
+				filesByPackage[pkg] = append(filesByPackage[pkg], file)
+			}
+		}
+	}
+
+	symbolPackages := make(lib.StringSet)
+	for pkg := range packages {
+		_, relevant, err := d.changedUsedSymbols(pkg, fmt.Sprintf("%s^", sha), sha, filesByPackage[pkg])
+		if err != nil {
+			return nil, err
+		}
+		if relevant && len(filesByPackage[pkg]) > 0 {
+			symbolPackages.Add(pkg)
+		}
+	}
+
+	return symbolPackages, nil
+}
+
+// commitChangesArtifacts returns true if any of the given files are relevant artifacts.
+func (d *diff) commitChangesArtifacts(commitFiles []string) bool {
+	artifacts := make(lib.StringSet)
+	artifacts.Add(d.changedArtifactFiles...)
+	for _, file := range commitFiles {
+		if artifacts.Contains(file) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // GoPackagerNamer determines a full package name given a partial package name.
 type goPackagerNamer func(partialPackageName string) string
 
@@ -304,8 +724,13 @@ func importPathNestedWithin(importPath, maybeOuterImportPath string) bool {
 	return importPath[len(maybeOuterImportPath)] == '/'
 }
 
-func recursiveDeps(packageName string) ([]string, *importer.PackageGraph, error) {
-	graph, err := importer.DefaultRecursiveImport(packageName)
+func recursiveDeps(packageName string, generators bool) ([]string, *importer.PackageGraph, error) {
+	recursiveImport := importer.DefaultRecursiveImport
+	if generators {
+		recursiveImport = importer.DefaultRecursiveImportWithGenerators
+	}
+
+	graph, err := recursiveImport(packageName)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// DOT renders the import graph of a summary in the Graphviz DOT language.
+// The root package and changed packages are highlighted, as are the packages and imports on the
+// shortest paths from the root to each changed package.
+func DOT(summary *Summary, opts GraphOptions) ([]byte, error) {
+	view, err := newGraphView(summary, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "digraph %s {\n", strconv.Quote(summary.RootImportPath))
+	buf.WriteString("  rankdir=LR;\n")
+	buf.WriteString("  node [shape=box, fontname=monospace];\n")
+
+	for _, node := range view.nodes {
+		var attrs []string
+		if node.Packages > 1 {
+			attrs = append(attrs, fmt.Sprintf("label=%s", strconv.Quote(fmt.Sprintf("%s/... (%d)", node.ID, node.Packages))))
+		}
+		if node.Root {
+			attrs = append(attrs, "style=filled", `fillcolor="lightblue"`)
+		} else if node.Changed {
+			attrs = append(attrs, "style=filled", `fillcolor="salmon"`)
+		}
+		if node.OnPath {
+			attrs = append(attrs, "penwidth=2")
+		}
+		writeDOTStatement(&buf, strconv.Quote(node.ID), attrs)
+	}
+
+	for _, edge := range view.edges {
+		var attrs []string
+		if edge.OnPath {
+			attrs = append(attrs, `color="red"`, "penwidth=2")
+		}
+		writeDOTStatement(&buf, fmt.Sprintf("%s -> %s", strconv.Quote(edge.From), strconv.Quote(edge.To)), attrs)
+	}
+
+	buf.WriteString("}\n")
+
+	return buf.Bytes(), nil
+}
+
+func writeDOTStatement(buf *bytes.Buffer, statement string, attrs []string) {
+	buf.WriteString("  ")
+	buf.WriteString(statement)
+	if len(attrs) > 0 {
+		buf.WriteString(" [")
+		for i, attr := range attrs {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(attr)
+		}
+		buf.WriteString("]")
+	}
+	buf.WriteString(";\n")
+}
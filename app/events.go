@@ -0,0 +1,107 @@
+package app
+
+import "sort"
+
+// Event types, in the order they are emitted.
+const (
+	EventStart   = "start"   // Emitted first, with the schema version, root import path and SHA
+	EventPackage = "package" // Emitted for each relevant changed package, in summary order
+	EventFile    = "file"    // Emitted for each relevant changed file, sorted
+	EventCommit  = "commit"  // Emitted for each relevant commit as it is determined, newest first
+	EventEnd     = "end"     // Emitted last, with results aggregated over all relevant commits
+)
+
+// Event is a part of a summary, emitted by Differ.DiffEvents as soon as it is determined.
+// Only the fields of the event's type are set. Events are not modified once emitted, and must not be modified
+// by the callback, as they share packages with the summary.
+type Event struct {
+	Type string `json:"type"` // One of the Event constants
+
+	SchemaVersion  int    `json:"schemaVersion,omitempty"`  // Start events: see SchemaVersion
+	RootImportPath string `json:"rootImportPath,omitempty"` // Start events
+	SHA            string `json:"sha,omitempty"`            // Start events
+
+	Package *Package     `json:"package,omitempty"` // Package events
+	File    *ChangedFile `json:"file,omitempty"`    // File events
+	Commit  *Commit      `json:"commit,omitempty"`  // Commit events, with the commit's risk score if scored
+
+	Risk *Risk `json:"risk,omitempty"` // End events: risk score of all relevant changes, if scored
+
+	Bump            string              `json:"bump,omitempty"`            // End events: semantic version bump suggested by relevant commits
+	Issues          []*Issue            `json:"issues,omitempty"`          // End events: issues referenced by relevant commits
+	OtherCommits    []*Commit           `json:"otherCommits,omitempty"`    // End events: commits in range that are not relevant, if requested
+	FileOwners      map[string][]string `json:"fileOwners,omitempty"`      // End events: owners by file, if requested
+	Owners          []*Owner            `json:"owners,omitempty"`          // End events: relevant changes by owner, if requested
+	Contributors    []*Contributor      `json:"contributors,omitempty"`    // End events: authors of the relevant changed lines, if blamed
+	Teams           []*Team             `json:"teams,omitempty"`           // End events: teams of the authors of the relevant changed lines, if blamed
+	Violations      []*PolicyViolation  `json:"violations,omitempty"`      // End events: policy violations introduced by the changes, if checked
+	LayerViolations []*LayerViolation   `json:"layerViolations,omitempty"` // End events: layer violations introduced by the changes, if checked
+	Cycles          []*DirectoryCycle   `json:"cycles,omitempty"`          // End events: directory cycles introduced by the changes, if checked
+	Findings        []*Finding          `json:"findings,omitempty"`        // End events: findings about relevant changes, if requested
+}
+
+// ChangedFile is a relevant changed file, as emitted in file events.
+type ChangedFile struct {
+	Path           string   `json:"path"`                     // Relative to the root of the Git repository
+	Packages       []string `json:"packages"`                 // Relevant changed packages of the file; empty for artifacts outside package directories
+	Classification string   `json:"classification,omitempty"` // Change classification, if classified
+	Owners         []string `json:"owners,omitempty"`         // Owners in CODEOWNERS, if requested
+}
+
+// emit calls the diff's event callback, if any.
+func (d *diff) emit(event *Event) error {
+	if d.emitEvent == nil {
+		return nil
+	}
+
+	return d.emitEvent(event)
+}
+
+// emitPackagesAndFiles emits an event for each relevant changed package and file.
+func (d *diff) emitPackagesAndFiles() error {
+	for _, pkg := range d.summary.Packages {
+		if err := d.emit(&Event{Type: EventPackage, Package: pkg}); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range d.summary.Files {
+		packages := []string{}
+		for _, pkg := range d.changedFilePackages[file] {
+			if d.relevantPackages.Contains(pkg) {
+				packages = append(packages, pkg)
+			}
+		}
+		sort.Strings(packages)
+		changedFile := &ChangedFile{
+			Path:           file,
+			Packages:       packages,
+			Classification: d.summary.FileClassifications[file],
+			Owners:         d.summary.FileOwners[file],
+		}
+		if err := d.emit(&Event{Type: EventFile, File: changedFile}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// endEvent returns the end event of a summary, with the results aggregated over all relevant commits.
+func endEvent(summary *Summary) *Event {
+	return &Event{
+		Type:            EventEnd,
+		Risk:            summary.Risk,
+		Bump:            summary.Bump,
+		Issues:          summary.Issues,
+		OtherCommits:    summary.OtherCommits,
+		FileOwners:      summary.FileOwners,
+		Owners:          summary.Owners,
+		Contributors:    summary.Contributors,
+		Teams:           summary.Teams,
+		Violations:      summary.Violations,
+		LayerViolations: summary.LayerViolations,
+		Cycles:          summary.Cycles,
+		Findings:        summary.Findings,
+	}
+}
@@ -0,0 +1,305 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alecholmes/tdiff/lib"
+)
+
+// testEvents returns an event of each type for a summary.
+func testEvents(summary *Summary) []*Event {
+	events := []*Event{{Type: EventStart, SchemaVersion: summary.SchemaVersion, RootImportPath: summary.RootImportPath, SHA: summary.SHA}}
+	for _, pkg := range summary.Packages {
+		events = append(events, &Event{Type: EventPackage, Package: pkg})
+	}
+	for _, file := range summary.Files {
+		events = append(events, &Event{Type: EventFile, File: &ChangedFile{
+			Path:           file,
+			Packages:       []string{"example.com/repo/lib"},
+			Classification: summary.FileClassifications[file],
+			Owners:         summary.FileOwners[file],
+		}})
+	}
+	for _, commit := range summary.Commits {
+		events = append(events, &Event{Type: EventCommit, Commit: commit})
+	}
+
+	return append(events, endEvent(summary))
+}
+
+func TestEventsNDJSON(t *testing.T) {
+	schema := loadSummarySchema(t)
+	eventSchema := schema["definitions"].(map[string]interface{})["event"].(map[string]interface{})
+
+	var buf bytes.Buffer
+	for i, event := range testEvents(testSummaries()["full"]) {
+		body, err := json.Marshal(event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(body, []byte("\n")) {
+			t.Fatalf("Expected event %d to be encoded on one line", i)
+		}
+		buf.Write(body)
+		buf.WriteByte('\n')
+
+		t.Run(fmt.Sprintf("event=%d type=%s", i, event.Type), func(t *testing.T) {
+			var value interface{}
+			if err := json.Unmarshal(body, &value); err != nil {
+				t.Fatal(err)
+			}
+			if errs := validateSchema(schema, eventSchema, value, "$"); len(errs) > 0 {
+				t.Fatalf("JSON of event does not match the schema:\n%s", strings.Join(errs, "\n"))
+			}
+		})
+	}
+
+	checkGolden(t, "events_full.golden.ndjson", buf.Bytes())
+}
+
+// testEventsDiff returns a diff of the test graph in which repo/lib/b and repo/util are relevant, and repo/lib/a
+// changed but is not relevant. File lib/shared.go belongs to both a relevant and an irrelevant package.
+func testEventsDiff(emit func(*Event) error) *diff {
+	relevantPackages := make(lib.StringSet)
+	relevantPackages.Add("repo/util", "repo/lib/b")
+	d := &diff{
+		emitEvent: emit,
+		summary: Summary{
+			RootImportPath: "repo/cmd",
+			Packages:       []*Package{},
+			Commits:        []*Commit{},
+		},
+		graph:                testGraphSummary().Graph,
+		relevantPackages:     relevantPackages,
+		changedArtifactFiles: []string{"docs/README.md"},
+		changedPackageFiles:  make(map[string][]string),
+		changedFilePackages:  make(map[string][]string),
+		config:               &Config{},
+	}
+	d.addChangedPackageFile("repo/util", "util/util.go")
+	d.addChangedPackageFile("repo/lib/b", "lib/shared.go")
+	d.addChangedPackageFile("repo/lib/a", "lib/shared.go")
+	d.addChangedPackageFile("repo/lib/a", "lib/a/a.go")
+
+	return d
+}
+
+func TestEmitPackagesAndFiles(t *testing.T) {
+	var events []string
+	d := testEventsDiff(func(event *Event) error {
+		switch event.Type {
+		case EventPackage:
+			events = append(events, fmt.Sprintf("package %s", event.Package.ImportPath))
+		case EventFile:
+			events = append(events, fmt.Sprintf("file %s %v", event.File.Path, event.File.Packages))
+		default:
+			events = append(events, event.Type)
+		}
+		return nil
+	})
+
+	if err := d.createPackageSummaries(false); err != nil {
+		t.Fatal(err)
+	}
+	d.determineRelevantFiles()
+	if err := d.emitPackagesAndFiles(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"package repo/lib/b",
+		"package repo/util",
+		"file docs/README.md []",
+		"file lib/shared.go [repo/lib/b]",
+		"file util/util.go [repo/util]",
+	}
+	if !reflect.DeepEqual(expected, events) {
+		t.Fatalf("Expected events %v but got %v", expected, events)
+	}
+}
+
+func TestEmitError(t *testing.T) {
+	emitErr := errors.New("closed pipe")
+
+	testCases := []struct {
+		failAt   int // Index of the event the callback fails on
+		expected int // Events emitted, including the failing one
+	}{
+		{failAt: 0, expected: 1},
+		{failAt: 1, expected: 2},
+		{failAt: 3, expected: 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("failAt=%d", tc.failAt), func(t *testing.T) {
+			emitted := 0
+			d := testEventsDiff(func(event *Event) error {
+				emitted++
+				if emitted > tc.failAt {
+					return emitErr
+				}
+				return nil
+			})
+			if err := d.createPackageSummaries(false); err != nil {
+				t.Fatal(err)
+			}
+			d.determineRelevantFiles()
+
+			if err := d.emitPackagesAndFiles(); err != emitErr {
+				t.Fatalf("Expected error %v but got %v", emitErr, err)
+			}
+			if emitted != tc.expected {
+				t.Fatalf("Expected %d events but got %d", tc.expected, emitted)
+			}
+		})
+	}
+
+	t.Run("DiffEvents", func(t *testing.T) {
+		differ := NewDiffer("", nil, true, false, nil)
+		summary, err := differ.DiffEvents("repo/cmd", "HEAD", Options{}, func(event *Event) error {
+			return emitErr
+		})
+		if err != emitErr {
+			t.Fatalf("Expected error %v but got %v", emitErr, err)
+		}
+		if summary != nil {
+			t.Fatalf("Expected no summary but got %v", summary)
+		}
+	})
+}
+
+// newTestEventsGit returns a Git repository with a base commit, and a commit per message changing files of the
+// diff of testEventsDiff, newest last.
+func newTestEventsGit(t *testing.T, messages ...string) (*lib.Git, string, func()) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "tdiff-events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	run := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Alice", "-c", "user.email=alice@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			cleanup()
+			t.Fatalf("git %v: %v", args, err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(file, body string) {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(file)), 0755); err != nil {
+			cleanup()
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, file), []byte(body), 0644); err != nil {
+			cleanup()
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q")
+	write("util/util.go", "package util\n")
+	run("add", "-A")
+	run("commit", "-q", "-m", "base")
+	base := run("rev-parse", "HEAD")
+
+	for i, message := range messages {
+		write("util/util.go", fmt.Sprintf("package util\n\nconst N = %d\n", i))
+		run("add", "-A")
+		run("commit", "-q", "-m", message)
+	}
+
+	return &lib.Git{RootDir: dir}, base, cleanup
+}
+
+func TestDetermineCommitsStreaming(t *testing.T) {
+	messages := []string{"fix: off by one PROJ-1", "feat: add N PROJ-2", "chore: bump N PROJ-1"}
+
+	for _, streaming := range []bool{false, true} {
+		t.Run(fmt.Sprintf("streaming=%t", streaming), func(t *testing.T) {
+			git, base, cleanup := newTestEventsGit(t, messages...)
+			defer cleanup()
+
+			var emitted []*Commit
+			var emittedRisks []float64
+			var emit func(*Event) error
+			if streaming {
+				emit = func(event *Event) error {
+					if event.Type == EventCommit {
+						emitted = append(emitted, event.Commit)
+						emittedRisks = append(emittedRisks, event.Commit.Risk.Score)
+					}
+					return nil
+				}
+			}
+			d := testEventsDiff(emit)
+			d.git = git
+			d.summary.SHA = base
+			d.summary.Bump = BumpNone
+			d.issueMatchers, _ = newIssueMatchers(d.config)
+			if err := d.createPackageSummaries(false); err != nil {
+				t.Fatal(err)
+			}
+			d.determineRelevantFiles()
+
+			if err := d.determineRisk(0); err != nil {
+				t.Fatal(err)
+			}
+			if err := d.determineCommits(true, false); err != nil {
+				t.Fatal(err)
+			}
+			d.determineIssues()
+
+			commits := d.summary.Commits
+			if streaming {
+				if len(commits) != 0 {
+					t.Fatalf("Expected streamed commits not to be retained but got %d", len(commits))
+				}
+				commits = emitted
+			}
+			var descriptions []string
+			for i, commit := range commits {
+				descriptions = append(descriptions, commit.Description)
+				if commit.Risk == nil || commit.Patch == "" {
+					t.Fatalf("Expected commit %s to have a risk score and patch", commit.Description)
+				}
+				if streaming && commit.Risk.Score != emittedRisks[i] {
+					t.Fatalf("Expected commit %s not to change after it was emitted", commit.Description)
+				}
+			}
+			expectedDescriptions := []string{"chore: bump N PROJ-1", "feat: add N PROJ-2", "fix: off by one PROJ-1"}
+			if !reflect.DeepEqual(expectedDescriptions, descriptions) {
+				t.Fatalf("Expected commits %v but got %v", expectedDescriptions, descriptions)
+			}
+
+			if d.summary.Bump != BumpMinor {
+				t.Fatalf("Expected bump %s but got %s", BumpMinor, d.summary.Bump)
+			}
+			var issues []string
+			for _, issue := range d.summary.Issues {
+				issues = append(issues, fmt.Sprintf("%s %v %v", issue.ID, len(issue.Commits), issue.Packages))
+			}
+			expectedIssues := []string{"PROJ-1 2 [repo/util]", "PROJ-2 1 [repo/util]"}
+			if !reflect.DeepEqual(expectedIssues, issues) {
+				t.Fatalf("Expected issues %v but got %v", expectedIssues, issues)
+			}
+			if d.summary.Risk == nil {
+				t.Fatal("Expected a risk score of all relevant changes")
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// mermaidEscaper escapes text in Mermaid labels, which are rendered as HTML, with Mermaid entity codes.
+var mermaidEscaper = strings.NewReplacer(`"`, "#quot;", "&", "#amp;", "<", "#lt;", ">", "#gt;")
+
+// Mermaid renders the import graph of a summary as a Mermaid flowchart.
+// The root package and changed packages are highlighted, as are the imports on the
+// shortest paths from the root to each changed package.
+func Mermaid(summary *Summary, opts GraphOptions) ([]byte, error) {
+	view, err := newGraphView(summary, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("flowchart LR\n")
+	buf.WriteString("  classDef root fill:#add8e6,stroke:#333\n")
+	buf.WriteString("  classDef changed fill:#fa8072,stroke:#333\n")
+
+	// Import paths aren't valid Mermaid node IDs, so nodes are numbered.
+	ids := make(map[string]string)
+	for i, node := range view.nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+
+		label := mermaidEscaper.Replace(node.ID)
+		if node.Packages > 1 {
+			label = fmt.Sprintf("%s/... (%d)", label, node.Packages)
+		}
+		if node.Changed {
+			label = fmt.Sprintf("%s<br/>%d files", label, node.Files)
+			if len(node.Classification) > 0 {
+				label = fmt.Sprintf("%s, %s", label, node.Classification)
+			}
+		}
+		fmt.Fprintf(&buf, "  %s[\"%s\"]\n", ids[node.ID], label)
+
+		if node.Root {
+			fmt.Fprintf(&buf, "  class %s root\n", ids[node.ID])
+		} else if node.Changed {
+			fmt.Fprintf(&buf, "  class %s changed\n", ids[node.ID])
+		}
+	}
+
+	var pathEdges []string
+	for i, edge := range view.edges {
+		fmt.Fprintf(&buf, "  %s --> %s\n", ids[edge.From], ids[edge.To])
+		if edge.OnPath {
+			pathEdges = append(pathEdges, strconv.Itoa(i))
+		}
+	}
+	if len(pathEdges) > 0 {
+		fmt.Fprintf(&buf, "  linkStyle %s stroke:red,stroke-width:2px\n", strings.Join(pathEdges, ","))
+	}
+
+	return buf.Bytes(), nil
+}
+
+// GraphML renders the import graph of a summary as GraphML.
+// Nodes have root, changed, onPath, classification, files and packages attributes,
+// and edges have an onPath attribute.
+func GraphML(summary *Summary, opts GraphOptions) ([]byte, error) {
+	view, err := newGraphView(summary, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	type data struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	}
+	type key struct {
+		ID   string `xml:"id,attr"`
+		For  string `xml:"for,attr"`
+		Name string `xml:"attr.name,attr"`
+		Type string `xml:"attr.type,attr"`
+	}
+	type node struct {
+		ID   string `xml:"id,attr"`
+		Data []data `xml:"data"`
+	}
+	type edge struct {
+		Source string `xml:"source,attr"`
+		Target string `xml:"target,attr"`
+		Data   []data `xml:"data"`
+	}
+	type graph struct {
+		ID          string `xml:"id,attr"`
+		EdgeDefault string `xml:"edgedefault,attr"`
+		Nodes       []node `xml:"node"`
+		Edges       []edge `xml:"edge"`
+	}
+	type graphML struct {
+		XMLName xml.Name `xml:"graphml"`
+		XMLNS   string   `xml:"xmlns,attr"`
+		Keys    []key    `xml:"key"`
+		Graph   graph    `xml:"graph"`
+	}
+
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []key{
+			{ID: "root", For: "node", Name: "root", Type: "boolean"},
+			{ID: "changed", For: "node", Name: "changed", Type: "boolean"},
+			{ID: "onPath", For: "node", Name: "onPath", Type: "boolean"},
+			{ID: "classification", For: "node", Name: "classification", Type: "string"},
+			{ID: "files", For: "node", Name: "files", Type: "int"},
+			{ID: "packages", For: "node", Name: "packages", Type: "int"},
+			{ID: "edgeOnPath", For: "edge", Name: "onPath", Type: "boolean"},
+		},
+		Graph: graph{ID: summary.RootImportPath, EdgeDefault: "directed"},
+	}
+	for _, n := range view.nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node{
+			ID: n.ID,
+			Data: []data{
+				{Key: "root", Value: strconv.FormatBool(n.Root)},
+				{Key: "changed", Value: strconv.FormatBool(n.Changed)},
+				{Key: "onPath", Value: strconv.FormatBool(n.OnPath)},
+				{Key: "classification", Value: n.Classification},
+				{Key: "files", Value: strconv.Itoa(n.Files)},
+				{Key: "packages", Value: strconv.Itoa(n.Packages)},
+			},
+		})
+	}
+	for _, e := range view.edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, edge{
+			Source: e.From,
+			Target: e.To,
+			Data:   []data{{Key: "edgeOnPath", Value: strconv.FormatBool(e.OnPath)}},
+		})
+	}
+
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), append(body, '\n')...), nil
+}
+
+// JSONGraph renders the import graph of a summary in the JSON Graph Format (http://jsongraphformat.info).
+// Node and edge metadata have the same attributes as GraphML.
+func JSONGraph(summary *Summary, opts GraphOptions) ([]byte, error) {
+	view, err := newGraphView(summary, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	type node struct {
+		Label    string                 `json:"label"`
+		Metadata map[string]interface{} `json:"metadata"`
+	}
+	type edge struct {
+		Source   string                 `json:"source"`
+		Target   string                 `json:"target"`
+		Metadata map[string]interface{} `json:"metadata"`
+	}
+	type graph struct {
+		ID       string          `json:"id"`
+		Directed bool            `json:"directed"`
+		Nodes    map[string]node `json:"nodes"`
+		Edges    []edge          `json:"edges"`
+	}
+
+	g := graph{
+		ID:       summary.RootImportPath,
+		Directed: true,
+		Nodes:    make(map[string]node),
+		Edges:    []edge{},
+	}
+	for _, n := range view.nodes {
+		g.Nodes[n.ID] = node{
+			Label: n.ID,
+			Metadata: map[string]interface{}{
+				"root":           n.Root,
+				"changed":        n.Changed,
+				"onPath":         n.OnPath,
+				"classification": n.Classification,
+				"files":          n.Files,
+				"packages":       n.Packages,
+			},
+		}
+	}
+	for _, e := range view.edges {
+		g.Edges = append(g.Edges, edge{
+			Source:   e.From,
+			Target:   e.To,
+			Metadata: map[string]interface{}{"onPath": e.OnPath},
+		})
+	}
+
+	body, err := json.MarshalIndent(struct {
+		Graph graph `json:"graph"`
+	}{g}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(body, '\n'), nil
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alecholmes/tdiff/source"
+)
+
+func TestGraphExportGolden(t *testing.T) {
+	// Node IDs with characters that need escaping in every format.
+	summary := testGraphSummary()
+	addFakePackage(summary.Graph, "repo/cmd", "repo/lib/a", "repo/lib/b", "repo/util", `repo/x<&"y>`)
+	addFakePackage(summary.Graph, `repo/x<&"y>`, "fmt")
+	summary.Packages = append(summary.Packages, &Package{ImportPath: `repo/x<&"y>`, Files: []string{"x/x.go"}, Classification: source.ChangeFormatting})
+
+	exports := map[string]func(*Summary, GraphOptions) ([]byte, error){
+		"dot":       DOT,
+		"mermaid":   Mermaid,
+		"graphml":   GraphML,
+		"jsongraph": JSONGraph,
+	}
+	extensions := map[string]string{"dot": "dot", "mermaid": "mmd", "graphml": "graphml", "jsongraph": "json"}
+
+	for name, export := range exports {
+		for _, opts := range []GraphOptions{{}, {PathsOnly: true, Collapse: []string{"repo/lib"}}} {
+			t.Run(fmt.Sprintf("format=%s,pathsOnly=%v", name, opts.PathsOnly), func(t *testing.T) {
+				actual, err := export(summary, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				variant := "full"
+				if opts.PathsOnly {
+					variant = "reduced"
+				}
+				checkGolden(t, fmt.Sprintf("graph_%s.golden.%s", variant, extensions[name]), actual)
+			})
+		}
+	}
+}
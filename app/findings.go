@@ -0,0 +1,154 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Finding rules.
+const (
+	RuleChangedFile       = "changed-file"        // A relevant file changed
+	RuleNewImport         = "new-import"          // A relevant Go file imports a package that no relevant Go file imported before
+	RuleBreakingAPIChange = "breaking-api-change" // A relevant package's exported API changed incompatibly
+	RulePolicyViolation   = "policy-violation"    // A package imports a package that a policy does not allow
+	RuleLayerViolation    = "layer-violation"     // A package imports a package in a higher layer
+	RuleDirectoryCycle    = "directory-cycle"     // Packages of directories import each other in a cycle
+)
+
+// Finding levels, from least to most severe.
+const (
+	LevelNote    = "note"
+	LevelWarning = "warning"
+	LevelError   = "error"
+)
+
+// FindingRules describes each finding rule, by rule.
+var FindingRules = map[string]string{
+	RuleChangedFile:       "A file that the package depends on changed",
+	RuleNewImport:         "A Go file that the package depends on imports a package that none of the changed Go files it depends on imported before",
+	RuleBreakingAPIChange: "The exported API of a package that the package depends on changed incompatibly",
+	RulePolicyViolation:   "A package imports a package that a dependency policy does not allow",
+	RuleLayerViolation:    "A package imports a package in a higher architectural layer",
+	RuleDirectoryCycle:    "Packages of directories import each other in a cycle",
+}
+
+// Finding is a single result about the relevant changes, located in a file of the Git repository.
+type Finding struct {
+	Rule    string `json:"rule"`           // One of the rule constants
+	Level   string `json:"level"`          // One of the level constants
+	Message string `json:"message"`        // Human readable description
+	File    string `json:"file"`           // File or package directory, relative to the root of the Git repository
+	Line    int    `json:"line,omitempty"` // Line in the file, if any
+}
+
+// determineFindings collects findings about the relevant changed files, the imports they add,
+// breaking changes to the exported API of relevant packages, if compared, and policy violations, layer violations
+// and directory cycles, if checked.
+// Findings are ordered by file, line and rule.
+func (d *diff) determineFindings() error {
+	findings := []*Finding{}
+
+	for _, file := range d.summary.Files {
+		message := fmt.Sprintf("%s changed", file)
+		if packages := d.changedFilePackages[file]; len(packages) > 0 {
+			message = fmt.Sprintf("%s changed in %s", file, strings.Join(packages, ", "))
+		}
+		findings = append(findings, &Finding{Rule: RuleChangedFile, Level: LevelNote, Message: message, File: file})
+	}
+
+	added, err := d.addedImports(d.summary.SHA, "HEAD", d.summary.Files)
+	if err != nil {
+		return err
+	}
+	for _, addedImport := range added {
+		findings = append(findings, &Finding{
+			Rule:    RuleNewImport,
+			Level:   LevelWarning,
+			Message: fmt.Sprintf("New import of %s", addedImport.Path),
+			File:    addedImport.file,
+			Line:    addedImport.Line,
+		})
+	}
+
+	for _, pkg := range d.summary.Packages {
+		for _, change := range pkg.APIChanges {
+			if !change.Breaking {
+				continue
+			}
+			findings = append(findings, &Finding{
+				Rule:    RuleBreakingAPIChange,
+				Level:   LevelError,
+				Message: fmt.Sprintf("Breaking API change: %s %s %s.%s", change.Change, change.Kind, pkg.ImportPath, change.Name),
+				File:    d.packageDir(pkg.ImportPath),
+			})
+		}
+	}
+
+	for _, violation := range d.summary.Violations {
+		file := violation.File
+		if len(file) == 0 {
+			file = d.packageDir(violation.Package)
+		}
+		findings = append(findings, &Finding{
+			Rule:    RulePolicyViolation,
+			Level:   LevelError,
+			Message: fmt.Sprintf("Policy %s violated: %s", violation.Policy, strings.Join(violation.Chain, " > ")),
+			File:    file,
+			Line:    violation.Line,
+		})
+	}
+
+	for _, violation := range d.summary.LayerViolations {
+		file := violation.File
+		if len(file) == 0 {
+			file = d.packageDir(violation.Package)
+		}
+		findings = append(findings, &Finding{
+			Rule:  RuleLayerViolation,
+			Level: LevelError,
+			Message: fmt.Sprintf("%s (layer %s) imports %s of higher layer %s",
+				violation.Package, violation.PackageLayer, violation.Import, violation.ImportLayer),
+			File: file,
+			Line: violation.Line,
+		})
+	}
+
+	for _, cycle := range d.summary.Cycles {
+		// The cycle is located at the import closing it.
+		closing := cycle.Imports[len(cycle.Imports)-1]
+		file := closing.File
+		if len(file) == 0 {
+			file = d.packageDir(closing.From)
+		}
+		findings = append(findings, &Finding{
+			Rule:    RuleDirectoryCycle,
+			Level:   LevelError,
+			Message: fmt.Sprintf("Directory cycle: %s", strings.Join(cycle.Chain, " > ")),
+			File:    file,
+			Line:    closing.Line,
+		})
+	}
+
+	sortFindings(findings)
+	d.summary.Findings = findings
+
+	return nil
+}
+
+// sortFindings orders findings by file, line, rule and message.
+func sortFindings(findings []*Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Message < b.Message
+	})
+}
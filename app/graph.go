@@ -0,0 +1,136 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alecholmes/tdiff/lib"
+	"github.com/alecholmes/tdiff/source"
+)
+
+// GraphOptions control how the package graph of a summary is exported.
+type GraphOptions struct {
+	PathsOnly bool     // Only include packages on the shortest paths from the root to changed packages.
+	Collapse  []string // Import path prefixes; the packages under each are collapsed into a single node.
+}
+
+// graphNode is a package, or a collapsed group of packages, in an exported graph.
+type graphNode struct {
+	ID             string // Import path, or prefix of collapsed packages
+	Root           bool   // Contains the root package
+	Changed        bool   // Contains a relevant changed package
+	OnPath         bool   // Contains a package on a shortest path from the root to a changed package
+	Classification string // Most significant change classification, if classified
+	Files          int    // Number of relevant changed files
+	Packages       int    // Number of packages in the node
+}
+
+// graphEdge is an import between nodes in an exported graph.
+type graphEdge struct {
+	From   string
+	To     string
+	OnPath bool // On a shortest path from the root to a changed package
+}
+
+// graphView is the package graph of a summary prepared for export.
+// Nodes and edges are sorted.
+type graphView struct {
+	nodes []*graphNode
+	edges []*graphEdge
+}
+
+func newGraphView(summary *Summary, opts GraphOptions) (*graphView, error) {
+	if summary.Graph == nil {
+		return nil, fmt.Errorf("Summary for %s has no package graph", summary.RootImportPath)
+	}
+
+	changed := make(map[string]*Package)
+	pathPackages := make(lib.StringSet)
+	pathEdges := make(map[[2]string]bool)
+	for _, pkg := range summary.Packages {
+		changed[pkg.ImportPath] = pkg
+
+		path, err := summary.Graph.ShortestPath(summary.RootImportPath, pkg.ImportPath)
+		if err != nil {
+			return nil, err
+		}
+		pathPackages.Add(path...)
+		for i := 1; i < len(path); i++ {
+			pathEdges[[2]string{path[i-1], path[i]}] = true
+		}
+	}
+	pathPackages.Add(summary.RootImportPath)
+
+	nodeID := func(pkg string) string {
+		id := pkg
+		for _, prefix := range opts.Collapse {
+			prefix = strings.TrimSuffix(prefix, "/")
+			if (pkg == prefix || strings.HasPrefix(pkg, prefix+"/")) && (id == pkg || len(prefix) > len(id)) {
+				id = prefix
+			}
+		}
+		return id
+	}
+
+	depMap := summary.Graph.ToMap()
+	nodes := make(map[string]*graphNode)
+	edges := make(map[[2]string]*graphEdge)
+	for pkg, deps := range depMap {
+		if opts.PathsOnly && !pathPackages.Contains(pkg) {
+			continue
+		}
+
+		id := nodeID(pkg)
+		node, ok := nodes[id]
+		if !ok {
+			node = &graphNode{ID: id}
+			nodes[id] = node
+		}
+		node.Packages++
+		node.Root = node.Root || pkg == summary.RootImportPath
+		node.OnPath = node.OnPath || pathPackages.Contains(pkg)
+		if changedPkg, ok := changed[pkg]; ok {
+			node.Changed = true
+			node.Files += len(changedPkg.Files)
+			node.Classification = source.MostSignificantChange(node.Classification, changedPkg.Classification)
+		}
+
+		for _, dep := range deps {
+			onPath := pathEdges[[2]string{pkg, dep}]
+			if opts.PathsOnly && !onPath {
+				continue
+			}
+
+			key := [2]string{id, nodeID(dep)}
+			if key[0] == key[1] {
+				continue
+			}
+			edge, ok := edges[key]
+			if !ok {
+				edge = &graphEdge{From: key[0], To: key[1]}
+				edges[key] = edge
+			}
+			edge.OnPath = edge.OnPath || onPath
+		}
+	}
+
+	view := &graphView{}
+	for _, node := range nodes {
+		view.nodes = append(view.nodes, node)
+	}
+	sort.Slice(view.nodes, func(i, j int) bool {
+		return view.nodes[i].ID < view.nodes[j].ID
+	})
+	for _, edge := range edges {
+		view.edges = append(view.edges, edge)
+	}
+	sort.Slice(view.edges, func(i, j int) bool {
+		if view.edges[i].From != view.edges[j].From {
+			return view.edges[i].From < view.edges[j].From
+		}
+		return view.edges[i].To < view.edges[j].To
+	})
+
+	return view, nil
+}
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"fmt"
+	"go/build"
+	"reflect"
+	"testing"
+
+	"github.com/alecholmes/tdiff/importer"
+	"github.com/alecholmes/tdiff/source"
+)
+
+// addFakePackage adds a package with the given production imports to a graph.
+func addFakePackage(graph *importer.PackageGraph, name string, imports ...string) *importer.Package {
+	pkg := &importer.Package{
+		Package: &build.Package{
+			ImportPath: name,
+			Imports:    imports,
+		},
+		ImportVendoredPaths: make(map[string]string),
+	}
+
+	for _, importPath := range imports {
+		pkg.ImportVendoredPaths[importPath] = importPath
+	}
+
+	graph.Packages[name] = pkg
+
+	return pkg
+}
+
+// testGraphSummary returns a summary of changes to repo/lib/b and repo/util in this graph:
+//
+//	repo/cmd -> [repo/lib/a, repo/lib/b, repo/util]
+//	repo/lib/a -> [repo/lib/b, fmt]
+//	repo/lib/b -> [fmt]
+//	repo/util -> [fmt]
+func testGraphSummary() *Summary {
+	graph := &importer.PackageGraph{Packages: make(map[string]*importer.Package)}
+	addFakePackage(graph, "repo/cmd", "repo/lib/a", "repo/lib/b", "repo/util")
+	addFakePackage(graph, "repo/lib/a", "repo/lib/b", "fmt")
+	addFakePackage(graph, "repo/lib/b", "fmt")
+	addFakePackage(graph, "repo/util", "fmt")
+	addFakePackage(graph, "fmt")
+
+	return &Summary{
+		RootImportPath: "repo/cmd",
+		Graph:          graph,
+		Packages: []*Package{
+			{ImportPath: "repo/lib/b", Files: []string{"lib/b/b.go", "lib/b/c.go"}, Classification: source.ChangeComment},
+			{ImportPath: "repo/util", Files: []string{"util/util.go"}, Classification: source.ChangeCode},
+		},
+	}
+}
+
+func TestNewGraphView(t *testing.T) {
+	testCases := []struct {
+		name          string
+		opts          GraphOptions
+		expectedNodes []*graphNode
+		expectedEdges []*graphEdge
+	}{
+		{
+			name: "whole graph",
+			expectedNodes: []*graphNode{
+				{ID: "fmt", Packages: 1},
+				{ID: "repo/cmd", Root: true, OnPath: true, Packages: 1},
+				{ID: "repo/lib/a", Packages: 1},
+				{ID: "repo/lib/b", Changed: true, OnPath: true, Classification: source.ChangeComment, Files: 2, Packages: 1},
+				{ID: "repo/util", Changed: true, OnPath: true, Classification: source.ChangeCode, Files: 1, Packages: 1},
+			},
+			expectedEdges: []*graphEdge{
+				{From: "repo/cmd", To: "repo/lib/a"},
+				{From: "repo/cmd", To: "repo/lib/b", OnPath: true},
+				{From: "repo/cmd", To: "repo/util", OnPath: true},
+				{From: "repo/lib/a", To: "fmt"},
+				{From: "repo/lib/a", To: "repo/lib/b"},
+				{From: "repo/lib/b", To: "fmt"},
+				{From: "repo/util", To: "fmt"},
+			},
+		},
+		{
+			name: "paths only",
+			opts: GraphOptions{PathsOnly: true},
+			expectedNodes: []*graphNode{
+				{ID: "repo/cmd", Root: true, OnPath: true, Packages: 1},
+				{ID: "repo/lib/b", Changed: true, OnPath: true, Classification: source.ChangeComment, Files: 2, Packages: 1},
+				{ID: "repo/util", Changed: true, OnPath: true, Classification: source.ChangeCode, Files: 1, Packages: 1},
+			},
+			expectedEdges: []*graphEdge{
+				{From: "repo/cmd", To: "repo/lib/b", OnPath: true},
+				{From: "repo/cmd", To: "repo/util", OnPath: true},
+			},
+		},
+		{
+			name: "collapsed",
+			opts: GraphOptions{Collapse: []string{"repo/lib/", "repo/util"}},
+			expectedNodes: []*graphNode{
+				{ID: "fmt", Packages: 1},
+				{ID: "repo/cmd", Root: true, OnPath: true, Packages: 1},
+				{ID: "repo/lib", Changed: true, OnPath: true, Classification: source.ChangeComment, Files: 2, Packages: 2},
+				{ID: "repo/util", Changed: true, OnPath: true, Classification: source.ChangeCode, Files: 1, Packages: 1},
+			},
+			expectedEdges: []*graphEdge{
+				{From: "repo/cmd", To: "repo/lib", OnPath: true},
+				{From: "repo/cmd", To: "repo/util", OnPath: true},
+				{From: "repo/lib", To: "fmt"},
+				{From: "repo/util", To: "fmt"},
+			},
+		},
+		{
+			name: "longest prefix collapses",
+			opts: GraphOptions{Collapse: []string{"repo", "repo/lib"}},
+			expectedNodes: []*graphNode{
+				{ID: "fmt", Packages: 1},
+				{ID: "repo", Root: true, Changed: true, OnPath: true, Classification: source.ChangeCode, Files: 1, Packages: 2},
+				{ID: "repo/lib", Changed: true, OnPath: true, Classification: source.ChangeComment, Files: 2, Packages: 2},
+			},
+			expectedEdges: []*graphEdge{
+				{From: "repo", To: "fmt"},
+				{From: "repo", To: "repo/lib", OnPath: true},
+				{From: "repo/lib", To: "fmt"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("case=%s", tc.name), func(t *testing.T) {
+			view, err := newGraphView(testGraphSummary(), tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tc.expectedNodes, view.nodes) {
+				t.Fatalf("Expected nodes %s but got %s", nodeString(tc.expectedNodes), nodeString(view.nodes))
+			}
+			if !reflect.DeepEqual(tc.expectedEdges, view.edges) {
+				t.Fatalf("Expected edges %s but got %s", edgeString(tc.expectedEdges), edgeString(view.edges))
+			}
+		})
+	}
+
+	if _, err := newGraphView(&Summary{RootImportPath: "repo/cmd"}, GraphOptions{}); err == nil {
+		t.Fatal("Expected an error for a summary without a graph")
+	}
+}
+
+func nodeString(nodes []*graphNode) string {
+	var s string
+	for _, node := range nodes {
+		s += fmt.Sprintf("\n%+v", *node)
+	}
+	return s
+}
+
+func edgeString(edges []*graphEdge) string {
+	var s string
+	for _, edge := range edges {
+		s += fmt.Sprintf("\n%+v", *edge)
+	}
+	return s
+}
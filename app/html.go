@@ -2,21 +2,197 @@ package app
 
 import (
 	"bytes"
+	"fmt"
 	"html/template"
+	"sort"
+	"strings"
 )
 
-var summaryTemplate = template.Must(template.New("").Parse(templateHTML))
+var summaryTemplate = template.Must(template.New("").Funcs(template.FuncMap{
+	"percent": func(fraction float64) string { return fmt.Sprintf("%.0f%%", fraction*100) },
+}).Parse(templateHTML))
 
+// HTML renders a summary as a self-contained interactive HTML report.
+// The report has a searchable list of commits, with their files and patches if included,
+// a collapsible tree of changed packages, a list of changed files, and a zoomable graph
+// of the shortest import paths from the root to each changed package.
 func HTML(summary *Summary) ([]byte, error) {
+	report := htmlReport{
+		Summary: summary,
+		Tree:    packageTree(summary.Packages),
+	}
+
+	if summary.Graph != nil {
+		view, err := newGraphView(summary, GraphOptions{PathsOnly: true})
+		if err != nil {
+			return nil, err
+		}
+
+		report.Graph = &htmlGraph{Nodes: []*htmlGraphNode{}, Edges: []*htmlGraphEdge{}}
+		for _, node := range view.nodes {
+			report.Graph.Nodes = append(report.Graph.Nodes, &htmlGraphNode{
+				ID:             node.ID,
+				Root:           node.Root,
+				Changed:        node.Changed,
+				Classification: node.Classification,
+				Files:          node.Files,
+			})
+		}
+		for _, edge := range view.edges {
+			report.Graph.Edges = append(report.Graph.Edges, &htmlGraphEdge{From: edge.From, To: edge.To})
+		}
+	}
+
 	var buf bytes.Buffer
-	if err := summaryTemplate.Execute(&buf, summary); err != nil {
+	if err := summaryTemplate.Execute(&buf, report); err != nil {
 		return nil, err
 	}
 
 	return buf.Bytes(), nil
 }
 
+type htmlReport struct {
+	*Summary
+	Tree  []*htmlTreeNode
+	Graph *htmlGraph
+}
+
+// HasImpact returns whether the impact of packages was computed.
+func (r htmlReport) HasImpact() bool {
+	for _, pkg := range r.Packages {
+		if pkg.Impact != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LineShare returns the fraction of all lines changed by contributors that a number of lines is.
+func (r htmlReport) LineShare(lines int) float64 {
+	total := 0
+	for _, contributor := range r.Contributors {
+		total += contributor.Lines
+	}
+	if total == 0 {
+		return 0
+	}
+
+	return float64(lines) / float64(total)
+}
+
+// htmlTreeNode is a node in the tree of changed packages, split by import path elements.
+type htmlTreeNode struct {
+	Name     string   // Import path elements of the node; chains of single children are merged
+	Package  *Package // The changed package at this node, if any
+	Children []*htmlTreeNode
+}
+
+// htmlGraph is the graph data embedded in the report for rendering by script.
+type htmlGraph struct {
+	Nodes []*htmlGraphNode `json:"nodes"`
+	Edges []*htmlGraphEdge `json:"edges"`
+}
+
+type htmlGraphNode struct {
+	ID             string `json:"id"`
+	Root           bool   `json:"root"`
+	Changed        bool   `json:"changed"`
+	Classification string `json:"classification"`
+	Files          int    `json:"files"`
+}
+
+type htmlGraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// packageTree arranges packages into a tree by their import path elements.
+func packageTree(packages []*Package) []*htmlTreeNode {
+	root := &htmlTreeNode{}
+	for _, pkg := range packages {
+		node := root
+		for _, name := range strings.Split(pkg.ImportPath, "/") {
+			var child *htmlTreeNode
+			for _, existing := range node.Children {
+				if existing.Name == name {
+					child = existing
+					break
+				}
+			}
+			if child == nil {
+				child = &htmlTreeNode{Name: name}
+				node.Children = append(node.Children, child)
+			}
+			node = child
+		}
+		node.Package = pkg
+	}
+
+	var compact func(node *htmlTreeNode)
+	compact = func(node *htmlTreeNode) {
+		for len(node.Children) == 1 && node.Package == nil && len(node.Name) > 0 {
+			child := node.Children[0]
+			node.Name = node.Name + "/" + child.Name
+			node.Package = child.Package
+			node.Children = child.Children
+		}
+		sort.Slice(node.Children, func(i, j int) bool {
+			return node.Children[i].Name < node.Children[j].Name
+		})
+		for _, child := range node.Children {
+			compact(child)
+		}
+	}
+	compact(root)
+
+	return root.Children
+}
+
 var templateHTML = `
+{{define "tree"}}
+    {{range .}}
+        {{if .Children}}
+            <details class="tree" open>
+                <summary>{{.Name}}</summary>
+                {{if .Package}}{{template "package" .Package}}{{end}}
+                {{template "tree" .Children}}
+            </details>
+        {{else}}
+            <div class="tree leaf">
+                <b>{{.Name}}</b>
+                {{if .Package}}{{template "package" .Package}}{{end}}
+            </div>
+        {{end}}
+    {{end}}
+{{end}}
+
+{{define "package"}}
+    <div class="searchable" data-search="{{.ImportPath}} {{range .Files}}{{.}} {{end}}">
+        <div class="meta">
+            {{.ImportPath}}
+            {{if .Classification}}<span class="tag">{{.Classification}}</span>{{end}}
+            {{with .Impact}}<span class="tag">depth {{.Depth}}, fan-in {{.FanIn}}, reach {{percent .Reach}}</span>{{end}}
+            {{range .Owners}}<span class="tag">{{.}}</span>{{end}}
+        </div>
+        {{if .PathFromRoot}}
+            <div class="path">
+                {{range .PathFromRoot}}
+                    > {{.}}
+                {{end}}
+            </div>
+        {{end}}
+        <details>
+            <summary>{{len .Files}} files</summary>
+            <ul>
+                {{range .Files}}
+                    <li>{{.}}</li>
+                {{end}}
+            </ul>
+        </details>
+    </div>
+{{end}}
+
 <!DOCTYPE html>
 <html lang="en">
     <head>
@@ -35,46 +211,335 @@ var templateHTML = `
                 padding-left: 1.5em;
                 text-indent: -1.5em;
             }
+
+            .tree {
+                padding-left: 1em;
+            }
+
+            .meta {
+                color: #555;
+            }
+
+            .tag {
+                background: #eee;
+                border-radius: 3px;
+                padding: 0 4px;
+            }
+
+            .hidden {
+                display: none;
+            }
+
+            #search {
+                font-family: monospace;
+                width: 40em;
+            }
+
+            #graph {
+                border: 1px solid #ccc;
+                cursor: grab;
+                height: 500px;
+                width: 100%;
+            }
+
+            #graph text {
+                font-family: monospace;
+                font-size: 12px;
+            }
+
+            #impact th, #impact td, .stats th, .stats td {
+                padding: 0 10px 0 0;
+                text-align: left;
+            }
+
+            .stats {
+                margin-bottom: 10px;
+            }
+
+            pre.patch {
+                background: #f6f6f6;
+                overflow-x: auto;
+                padding: 5px;
+            }
         </style>
     </head>
     <body>
+        <h1>{{.RootImportPath}}</h1>
+        <div class="section">
+            <p>Changes after {{.SHA}}: {{len .Commits}} commits, {{len .Packages}} packages, {{len .Files}} files</p>
+            <input id="search" type="search" placeholder="Filter commits, packages and files">
+        </div>
+
+        {{if .Graph}}
+            <h1>Graph</h1>
+            <div class="section">
+                <p class="meta">Scroll to zoom, drag to pan, click a package to filter by it.</p>
+                <svg id="graph"></svg>
+            </div>
+        {{end}}
+
         <h1>Commits</h1>
         <div class="section">
             {{range .Commits}}
-                <h3>{{.SHA}}</h3>
-                <div class="section">
-                    <p><b>{{.Description}}</b></p>
-                    {{range .RelevantPackages}}
-                        <div class="path">
-                            {{range .PathFromRoot}}
-                                > {{.}}
+                <details class="searchable" data-search="{{.SHA}} {{.Description}} {{range .Files}}{{.}} {{end}}{{range .RelevantPackages}}{{.ImportPath}} {{end}}{{range .Issues}}{{.}} {{end}}">
+                    <summary>
+                        <b>{{.SHA}}</b> {{.Description}}
+                        {{if .Classification}}<span class="tag">{{.Classification}}</span>{{end}}
+                        {{range .Issues}}<span class="tag">{{.}}</span>{{end}}
+                    </summary>
+                    <div class="section">
+                        {{range .RelevantPackages}}
+                            <div class="path">
+                                {{range .PathFromRoot}}
+                                    > {{.}}
+                                {{end}}
+                            </div>
+                        {{end}}
+                        <ul>
+                            {{range .Files}}
+                                <li>{{.}}</li>
                             {{end}}
-                        </div>
-                    {{end}}
-                </div>
+                        </ul>
+                        {{if .Patch}}
+                            <details>
+                                <summary>Patch</summary>
+                                <pre class="patch">{{.Patch}}</pre>
+                            </details>
+                        {{end}}
+                    </div>
+                </details>
             {{end}}
         </div>
 
+        {{if .Contributors}}
+            <h1>Contributors</h1>
+            <div class="section">
+                <p class="meta">Authors of the lines of relevant files changed in range.</p>
+                <table class="stats">
+                    <tr><th>Author</th><th>Team</th><th>Lines</th><th>Share</th><th>Files</th></tr>
+                    {{range .Contributors}}
+                        <tr class="searchable" data-search="{{.Name}} {{.Email}} {{.Team}}">
+                            <td>{{.Name}} &lt;{{.Email}}&gt;</td>
+                            <td>{{.Team}}</td>
+                            <td>{{.Lines}}</td>
+                            <td>{{percent ($.LineShare .Lines)}}</td>
+                            <td>{{.Files}}</td>
+                        </tr>
+                    {{end}}
+                </table>
+                {{if .Teams}}
+                    <table class="stats">
+                        <tr><th>Team</th><th>Lines</th><th>Share</th><th>Contributors</th></tr>
+                        {{range .Teams}}
+                            <tr class="searchable" data-search="{{.Name}} {{range .Contributors}}{{.}} {{end}}">
+                                <td>{{.Name}}</td>
+                                <td>{{.Lines}}</td>
+                                <td>{{percent ($.LineShare .Lines)}}</td>
+                                <td>{{len .Contributors}}</td>
+                            </tr>
+                        {{end}}
+                    </table>
+                {{end}}
+            </div>
+        {{end}}
+
+        {{if .Issues}}
+            <h1>Issues</h1>
+            <div class="section">
+                <ul>
+                    {{range .Issues}}
+                        <li class="searchable" data-search="{{.ID}} {{range .Commits}}{{.}} {{end}}{{range .Packages}}{{.}} {{end}}">
+                            {{if .URL}}<a href="{{.URL}}">{{.ID}}</a>{{else}}{{.ID}}{{end}}:
+                            {{range $i, $pkg := .Packages}}{{if $i}}, {{end}}{{$pkg}}{{end}}
+                            <span class="meta">({{len .Commits}} commits)</span>
+                        </li>
+                    {{end}}
+                </ul>
+            </div>
+        {{end}}
+
         <h1>Packages</h1>
         <div class="section">
-            {{range .Packages}}
-                <p><b>{{.ImportPath}}</b></p>
-                <div class="path">
-                    {{range .PathFromRoot}}
-                        > {{.}}
-                    {{end}}
-                </div>
-            {{end}}
+            {{template "tree" .Tree}}
         </div>
 
+        {{if .HasImpact}}
+            <h1>Impact</h1>
+            <div class="section">
+                <p class="meta">Click a column to sort.</p>
+                <table id="impact">
+                    <thead>
+                        <tr>
+                            <th data-sort="name">Package</th>
+                            <th data-sort="depth" data-numeric>Depth</th>
+                            <th data-sort="fanin" data-numeric data-descending>Fan-in</th>
+                            <th data-sort="reach" data-numeric data-descending>Reach</th>
+                        </tr>
+                    </thead>
+                    <tbody>
+                        {{range .Packages}}
+                            {{if .Impact}}
+                                <tr class="searchable" data-search="{{.ImportPath}}" data-name="{{.ImportPath}}" data-depth="{{.Impact.Depth}}" data-fanin="{{.Impact.FanIn}}" data-reach="{{.Impact.Reach}}">
+                                    <td>{{.ImportPath}}</td>
+                                    <td>{{.Impact.Depth}}</td>
+                                    <td>{{.Impact.FanIn}}</td>
+                                    <td>{{percent .Impact.Reach}}</td>
+                                </tr>
+                            {{end}}
+                        {{end}}
+                    </tbody>
+                </table>
+            </div>
+        {{end}}
+
         <h1>Files</h1>
         <div class="section">
             <ul>
                 {{range .Files}}
-                    <li>{{.}}</li>
+                    <li class="searchable" data-search="{{.}} {{range index $.FileOwners .}}{{.}} {{end}}">
+                        {{.}}
+                        {{range index $.FileOwners .}}<span class="tag">{{.}}</span>{{end}}
+                    </li>
                 {{end}}
             </ul>
         </div>
+
+        <script>
+            const graph = {{.Graph}};
+
+            const search = document.getElementById("search");
+            function filter(query) {
+                query = query.toLowerCase();
+                document.querySelectorAll(".searchable").forEach(function(el) {
+                    const matches = el.dataset.search.toLowerCase().indexOf(query) >= 0;
+                    el.classList.toggle("hidden", !matches);
+                });
+                document.querySelectorAll("details.tree").forEach(function(el) {
+                    el.classList.toggle("hidden", query.length > 0 && el.querySelector(".searchable:not(.hidden)") === null);
+                });
+            }
+            search.addEventListener("input", function() { filter(search.value); });
+
+            document.querySelectorAll("#impact th").forEach(function(th) {
+                th.style.cursor = "pointer";
+                th.addEventListener("click", function() {
+                    const key = th.dataset.sort;
+                    const numeric = "numeric" in th.dataset;
+                    const direction = "descending" in th.dataset ? -1 : 1;
+                    const tbody = document.querySelector("#impact tbody");
+                    const rows = Array.from(tbody.querySelectorAll("tr"));
+                    rows.sort(function(a, b) {
+                        const x = numeric ? parseFloat(a.dataset[key]) : a.dataset[key];
+                        const y = numeric ? parseFloat(b.dataset[key]) : b.dataset[key];
+                        if (x !== y) {
+                            return (x < y ? -1 : 1) * direction;
+                        }
+                        return a.dataset.name < b.dataset.name ? -1 : 1;
+                    });
+                    rows.forEach(function(row) { tbody.appendChild(row); });
+                });
+            });
+
+            function drawGraph(svg, graph) {
+                const ns = "http://www.w3.org/2000/svg";
+                const nodeWidth = 320, nodeHeight = 28, xGap = 60, yGap = 16;
+
+                // Lay out nodes in columns by their distance from the root.
+                const depth = {};
+                const children = {};
+                graph.edges.forEach(function(e) { (children[e.from] = children[e.from] || []).push(e.to); });
+                const roots = graph.nodes.filter(function(n) { return n.root; }).map(function(n) { return n.id; });
+                roots.forEach(function(id) { depth[id] = 0; });
+                for (let queue = roots.slice(); queue.length > 0; ) {
+                    const id = queue.shift();
+                    (children[id] || []).forEach(function(child) {
+                        if (!(child in depth)) {
+                            depth[child] = depth[id] + 1;
+                            queue.push(child);
+                        }
+                    });
+                }
+
+                const columns = {};
+                const pos = {};
+                graph.nodes.forEach(function(n) {
+                    const d = depth[n.id] || 0;
+                    const row = columns[d] = (columns[d] || 0) + 1;
+                    pos[n.id] = {x: d * (nodeWidth + xGap) + 10, y: (row - 1) * (nodeHeight + yGap) + 10};
+                });
+
+                const view = svg.ownerDocument.createElementNS(ns, "g");
+                svg.appendChild(view);
+
+                graph.edges.forEach(function(e) {
+                    const line = document.createElementNS(ns, "line");
+                    line.setAttribute("x1", pos[e.from].x + nodeWidth);
+                    line.setAttribute("y1", pos[e.from].y + nodeHeight / 2);
+                    line.setAttribute("x2", pos[e.to].x);
+                    line.setAttribute("y2", pos[e.to].y + nodeHeight / 2);
+                    line.setAttribute("stroke", "#c00");
+                    view.appendChild(line);
+                });
+
+                graph.nodes.forEach(function(n) {
+                    const g = document.createElementNS(ns, "g");
+                    g.setAttribute("transform", "translate(" + pos[n.id].x + "," + pos[n.id].y + ")");
+                    g.style.cursor = "pointer";
+                    g.addEventListener("click", function() {
+                        search.value = n.id;
+                        filter(n.id);
+                    });
+
+                    const rect = document.createElementNS(ns, "rect");
+                    rect.setAttribute("width", nodeWidth);
+                    rect.setAttribute("height", nodeHeight);
+                    rect.setAttribute("fill", n.root ? "lightblue" : (n.changed ? "salmon" : "white"));
+                    rect.setAttribute("stroke", "#333");
+                    g.appendChild(rect);
+
+                    const text = document.createElementNS(ns, "text");
+                    text.setAttribute("x", 5);
+                    text.setAttribute("y", 18);
+                    text.textContent = n.id;
+                    g.appendChild(text);
+
+                    const title = document.createElementNS(ns, "title");
+                    title.textContent = n.id + (n.changed ? " (" + n.files + " files" + (n.classification ? ", " + n.classification : "") + ")" : "");
+                    g.appendChild(title);
+
+                    view.appendChild(g);
+                });
+
+                // Zoom with the scroll wheel and pan by dragging.
+                let scale = 1, tx = 0, ty = 0, drag = null;
+                function update() {
+                    view.setAttribute("transform", "translate(" + tx + "," + ty + ") scale(" + scale + ")");
+                }
+                svg.addEventListener("wheel", function(e) {
+                    e.preventDefault();
+                    const rect = svg.getBoundingClientRect();
+                    const mx = e.clientX - rect.left, my = e.clientY - rect.top;
+                    const factor = e.deltaY < 0 ? 1.1 : 1 / 1.1;
+                    tx = mx - (mx - tx) * factor;
+                    ty = my - (my - ty) * factor;
+                    scale *= factor;
+                    update();
+                });
+                svg.addEventListener("mousedown", function(e) { drag = {x: e.clientX - tx, y: e.clientY - ty}; });
+                window.addEventListener("mouseup", function() { drag = null; });
+                window.addEventListener("mousemove", function(e) {
+                    if (drag) {
+                        tx = e.clientX - drag.x;
+                        ty = e.clientY - drag.y;
+                        update();
+                    }
+                });
+            }
+
+            if (graph) {
+                drawGraph(document.getElementById("graph"), graph);
+            }
+        </script>
     </body>
 </html>
 `
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecholmes/tdiff/importer"
+)
+
+func TestHTML(t *testing.T) {
+	summary := testSummaries()["full"]
+	summary.Commits[0].Patch = "diff --git a/lib/lib.go b/lib/lib.go\n+// </pre><script>alert(\"patch\")</script>\n"
+	summary.Commits[0].Description = "fix: <b>bold</b> description"
+	summary.Graph = &importer.PackageGraph{Packages: make(map[string]*importer.Package)}
+	addFakePackage(summary.Graph, "example.com/repo/cmd", "example.com/repo/lib")
+	addFakePackage(summary.Graph, "example.com/repo/lib")
+
+	body, err := HTML(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(body)
+
+	for _, unescaped := range []string{`<script>alert("patch")</script>`, "<b>bold</b>"} {
+		if strings.Contains(html, unescaped) {
+			t.Fatalf("Expected %s to be escaped", unescaped)
+		}
+	}
+	for _, expected := range []string{
+		"&lt;/pre&gt;&lt;script&gt;alert(&#34;patch&#34;)&lt;/script&gt;",
+		"fix: &lt;b&gt;bold&lt;/b&gt; description",
+		"example.com/repo/lib",
+		"PROJ-1",
+		"alice@example.com",
+	} {
+		if !strings.Contains(html, expected) {
+			t.Fatalf("Expected HTML to contain %s", expected)
+		}
+	}
+
+	// Summaries without optional sections or a graph render too.
+	if _, err := HTML(testSummaries()["minimal"]); err != nil {
+		t.Fatal(err)
+	}
+}
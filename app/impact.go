@@ -0,0 +1,92 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Package orderings for Options.SortBy.
+const (
+	SortByName  = "name"   // By import path
+	SortByDepth = "depth"  // Closest to the root first
+	SortByFanIn = "fan-in" // Most dependents first
+	SortByReach = "reach"  // Largest fraction of the graph affected first
+)
+
+// Impact describes how much of the root package's graph a changed package affects.
+type Impact struct {
+	Depth int     `json:"depth"` // Number of imports on the shortest path from the root
+	FanIn int     `json:"fanIn"` // Number of packages reachable from the root that transitively import the package
+	Reach float64 `json:"reach"` // Fraction of packages outside GOROOT reachable from the root through production imports affected by the package, including itself
+}
+
+// determineImpact computes the impact of each relevant package.
+func (d *diff) determineImpact() error {
+	// Reach only counts production packages outside GOROOT, so that it does not shrink as standard library
+	// packages or test dependencies are imported.
+	production := productionGraph(d.graph.WithoutTests(), d.summary.RootImportPath)
+	productionPackages := 0
+	for _, pkg := range production.Packages {
+		if !pkg.Goroot {
+			productionPackages++
+		}
+	}
+
+	for _, pkg := range d.summary.Packages {
+		path, err := d.graph.ShortestPath(d.summary.RootImportPath, pkg.ImportPath)
+		if err != nil {
+			return err
+		}
+		if len(path) == 0 {
+			return fmt.Errorf("Expected path between %s and %s", d.summary.RootImportPath, pkg.ImportPath)
+		}
+
+		dependents, err := d.graph.Dependents(pkg.ImportPath)
+		if err != nil {
+			return err
+		}
+
+		// Packages only reachable through test imports affect none of the production packages.
+		var reach float64
+		if _, ok := production.Packages[pkg.ImportPath]; ok {
+			productionDependents, err := production.Dependents(pkg.ImportPath)
+			if err != nil {
+				return err
+			}
+			reach = float64(len(productionDependents)+1) / float64(productionPackages)
+		}
+
+		pkg.Impact = &Impact{
+			Depth: len(path) - 1,
+			FanIn: len(dependents),
+			Reach: reach,
+		}
+	}
+
+	return nil
+}
+
+// sortPackages orders the package summaries by one of the SortBy constants.
+// Ties are ordered by import path.
+func (d *diff) sortPackages(sortBy string) error {
+	var less func(a, b *Impact) bool
+	switch sortBy {
+	case "", SortByName:
+		return nil
+	case SortByDepth:
+		less = func(a, b *Impact) bool { return a.Depth < b.Depth }
+	case SortByFanIn:
+		less = func(a, b *Impact) bool { return a.FanIn > b.FanIn }
+	case SortByReach:
+		less = func(a, b *Impact) bool { return a.Reach > b.Reach }
+	default:
+		return fmt.Errorf("Unknown package ordering `%s`", sortBy)
+	}
+
+	// Packages are already ordered by import path, so a stable sort breaks ties by it.
+	sort.SliceStable(d.summary.Packages, func(i, j int) bool {
+		return less(d.summary.Packages[i].Impact, d.summary.Packages[j].Impact)
+	})
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/alecholmes/tdiff/importer"
+)
+
+// testImpactDiff returns a diff of changes to repo/a, repo/b, repo/testutil and repo/util in this graph:
+//
+//	repo/cmd -> [repo/a, repo/util, fmt], test imports [repo/testutil]
+//	repo/a -> [repo/b, fmt]
+//	repo/b -> [fmt]
+//	repo/util -> [fmt]
+//	repo/testutil -> [repo/util]
+//
+// The graph has four production packages outside GOROOT: repo/cmd, repo/a, repo/b and repo/util.
+func testImpactDiff() *diff {
+	graph := &importer.PackageGraph{Packages: make(map[string]*importer.Package)}
+	cmd := addFakePackage(graph, "repo/cmd", "repo/a", "repo/util", "fmt")
+	cmd.TestImports = []string{"repo/testutil"}
+	cmd.ImportVendoredPaths["repo/testutil"] = "repo/testutil"
+	addFakePackage(graph, "repo/a", "repo/b", "fmt")
+	addFakePackage(graph, "repo/b", "fmt")
+	addFakePackage(graph, "repo/util", "fmt")
+	addFakePackage(graph, "repo/testutil", "repo/util")
+	addFakePackage(graph, "fmt").Goroot = true
+
+	d := &diff{graph: graph, summary: Summary{RootImportPath: "repo/cmd"}}
+	for _, pkg := range []string{"repo/a", "repo/b", "repo/testutil", "repo/util"} {
+		d.summary.Packages = append(d.summary.Packages, &Package{ImportPath: pkg})
+	}
+
+	return d
+}
+
+func TestDetermineImpact(t *testing.T) {
+	d := testImpactDiff()
+	if err := d.determineImpact(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]Impact{
+		"repo/a":        {Depth: 1, FanIn: 1, Reach: 0.5},
+		"repo/b":        {Depth: 2, FanIn: 2, Reach: 0.75},
+		"repo/testutil": {Depth: 1, FanIn: 1, Reach: 0}, // Only reachable through test imports
+		"repo/util":     {Depth: 1, FanIn: 2, Reach: 0.5},
+	}
+	for _, pkg := range d.summary.Packages {
+		t.Run(fmt.Sprintf("package=%s", pkg.ImportPath), func(t *testing.T) {
+			if !reflect.DeepEqual(expected[pkg.ImportPath], *pkg.Impact) {
+				t.Fatalf("Expected %+v but got %+v", expected[pkg.ImportPath], *pkg.Impact)
+			}
+		})
+	}
+
+	// Importing more standard library packages does not change reach.
+	d = testImpactDiff()
+	addFakePackage(d.graph, "strings").Goroot = true
+	d.graph.Packages["repo/b"].Imports = append(d.graph.Packages["repo/b"].Imports, "strings")
+	d.graph.Packages["repo/b"].ImportVendoredPaths["strings"] = "strings"
+	if err := d.determineImpact(); err != nil {
+		t.Fatal(err)
+	}
+	if reach := d.summary.Packages[1].Impact.Reach; reach != 0.75 {
+		t.Fatalf("Expected reach 0.75 but got %v", reach)
+	}
+}
+
+func TestSortPackages(t *testing.T) {
+	testCases := []struct {
+		sortBy   string
+		expected []string
+	}{
+		{sortBy: "", expected: []string{"repo/a", "repo/b", "repo/testutil", "repo/util"}},
+		{sortBy: SortByName, expected: []string{"repo/a", "repo/b", "repo/testutil", "repo/util"}},
+		{sortBy: SortByDepth, expected: []string{"repo/a", "repo/testutil", "repo/util", "repo/b"}},
+		{sortBy: SortByFanIn, expected: []string{"repo/b", "repo/util", "repo/a", "repo/testutil"}},
+		{sortBy: SortByReach, expected: []string{"repo/b", "repo/a", "repo/util", "repo/testutil"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("sortBy=%s", tc.sortBy), func(t *testing.T) {
+			d := testImpactDiff()
+			if err := d.determineImpact(); err != nil {
+				t.Fatal(err)
+			}
+			if err := d.sortPackages(tc.sortBy); err != nil {
+				t.Fatal(err)
+			}
+
+			var actual []string
+			for _, pkg := range d.summary.Packages {
+				actual = append(actual, pkg.ImportPath)
+			}
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("Expected order %v but got %v", tc.expected, actual)
+			}
+		})
+	}
+
+	if err := testImpactDiff().sortPackages("size"); err == nil {
+		t.Fatalf("Expected error for unknown ordering but got none")
+	}
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/alecholmes/tdiff/importer"
+	"github.com/alecholmes/tdiff/lib"
+)
+
+// fileDependents returns the reachable packages that use a changed file as a build input,
+// other than by the file being in the package's directory.
+// The file name is relative to the root of the Git repository.
+type fileDependents func(file string) []string
+
+// embedDependents returns a fileDependents for files embedded by reachable packages via //go:embed.
+func embedDependents(graph *importer.PackageGraph, gitRoot string) fileDependents {
+	var embedders []string
+	for importPath, pkg := range graph.Packages {
+		if !pkg.Goroot && len(pkg.EmbedPatterns)+len(pkg.TestEmbedPatterns)+len(pkg.XTestEmbedPatterns) > 0 {
+			embedders = append(embedders, importPath)
+		}
+	}
+	sort.Strings(embedders)
+
+	return func(file string) []string {
+		absFile := filepath.Join(gitRoot, file)
+
+		var packages []string
+		for _, importPath := range embedders {
+			if graph.Packages[importPath].EmbedsFile(absFile) {
+				packages = append(packages, importPath)
+			}
+		}
+
+		return packages
+	}
+}
+
+// cgoDependents returns a fileDependents for C, assembly and other non-Go files compiled
+// into reachable packages, including headers included from other directories.
+func cgoDependents(graph *importer.PackageGraph, gitRoot string) fileDependents {
+	inputPackages := make(map[string][]string) // Reachable packages by absolute input file
+	for importPath, pkg := range graph.Packages {
+		if pkg.Goroot {
+			continue
+		}
+		for _, input := range pkg.CgoInputs() {
+			inputPackages[input] = append(inputPackages[input], importPath)
+		}
+	}
+	for _, packages := range inputPackages {
+		sort.Strings(packages)
+	}
+
+	return func(file string) []string {
+		return inputPackages[filepath.Join(gitRoot, file)]
+	}
+}
+
+// generateDependents returns a fileDependents for the inputs of //go:generate directives in
+// reachable packages: files named by directive arguments, such as templates, and files
+// matching the inputs the config declares for the directive's generator.
+func generateDependents(graph *importer.PackageGraph, config *Config, gitRoot, packagePrefix string) (fileDependents, error) {
+	inputPackages := make(map[string]lib.StringSet) // Reachable packages by absolute input file
+	rulePackages := make(map[*GeneratorRule]lib.StringSet)
+	for importPath, pkg := range graph.Packages {
+		if pkg.Goroot {
+			continue
+		}
+
+		directives, err := pkg.GenerateDirectives()
+		if err != nil {
+			return nil, err
+		}
+		for _, directive := range directives {
+			for _, input := range directive.Inputs(pkg.Dir) {
+				if _, ok := inputPackages[input]; !ok {
+					inputPackages[input] = make(lib.StringSet)
+				}
+				inputPackages[input].Add(importPath)
+			}
+
+			tool := directive.Tool(importPath)
+			for _, rule := range config.Generators {
+				if rule.Tool == tool || matchPackagePattern(rule.Tool, tool, packagePrefix) {
+					if _, ok := rulePackages[rule]; !ok {
+						rulePackages[rule] = make(lib.StringSet)
+					}
+					rulePackages[rule].Add(importPath)
+				}
+			}
+		}
+	}
+
+	return func(file string) []string {
+		packages := make(lib.StringSet)
+		packages.Add(inputPackages[filepath.Join(gitRoot, file)].Slice()...)
+		for rule, packagesForRule := range rulePackages {
+			if matchAnyGlob(rule.Inputs, file) {
+				packages.Add(packagesForRule.Slice()...)
+			}
+		}
+
+		result := packages.Slice()
+		sort.Strings(result)
+		return result
+	}, nil
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/alecholmes/tdiff/lib"
+)
+
+// Issue is an issue referenced by relevant commits.
+type Issue struct {
+	ID       string   `json:"id"`            // Reference as found in commit messages, e.g. "PROJ-1234"
+	URL      string   `json:"url,omitempty"` // Link to the issue, if configured
+	Commits  []string `json:"commits"`       // SHAs of relevant commits referencing the issue
+	Packages []string `json:"packages"`      // Relevant packages changed by those commits
+}
+
+type issueMatcher struct {
+	pattern *regexp.Regexp
+	url     string
+	exclude *regexp.Regexp // Matches that are not references, if any
+}
+
+// newIssueMatchers compiles the issue rules of a config, or the default rules if there are none.
+// Patterns that match the empty string are rejected, since every message would reference an empty issue.
+func newIssueMatchers(config *Config) ([]*issueMatcher, error) {
+	rules := config.Issues
+	if len(rules) == 0 {
+		rules = DefaultIssueRules
+	}
+
+	matchers := make([]*issueMatcher, 0, len(rules))
+	for _, rule := range rules {
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid issue pattern `%s`: %v", rule.Pattern, err)
+		}
+		if pattern.MatchString("") {
+			return nil, fmt.Errorf("Invalid issue pattern `%s`: matches the empty string", rule.Pattern)
+		}
+		matcher := &issueMatcher{pattern: pattern, url: rule.URL}
+		if len(rule.Exclude) > 0 {
+			if matcher.exclude, err = regexp.Compile(rule.Exclude); err != nil {
+				return nil, fmt.Errorf("Invalid issue exclude pattern `%s`: %v", rule.Exclude, err)
+			}
+		}
+		matchers = append(matchers, matcher)
+	}
+
+	return matchers, nil
+}
+
+// findIssues returns the unique issue references in a commit message, in the order of the rules
+// and then of their appearance, and records links to them. Empty and excluded matches are ignored.
+func (d *diff) findIssues(message string) []string {
+	var issues []string
+	found := make(lib.StringSet)
+	for _, matcher := range d.issueMatchers {
+		for _, match := range matcher.pattern.FindAllStringSubmatchIndex(message, -1) {
+			id := message[match[0]:match[1]]
+			if len(id) == 0 || found.Contains(id) || (matcher.exclude != nil && matcher.exclude.MatchString(id)) {
+				continue
+			}
+			found.Add(id)
+			issues = append(issues, id)
+
+			if _, ok := d.issueURLs[id]; !ok && len(matcher.url) > 0 {
+				d.issueURLs[id] = string(matcher.pattern.ExpandString(nil, matcher.url, message, match))
+			}
+		}
+	}
+
+	return issues
+}
+
+// addCommitIssues records the issues referenced by a relevant commit.
+func (d *diff) addCommitIssues(commit *Commit) {
+	if d.issues == nil {
+		d.issues = make(map[string]*Issue)
+		d.issuePackages = make(map[string]lib.StringSet)
+	}
+
+	for _, id := range commit.Issues {
+		issue, ok := d.issues[id]
+		if !ok {
+			issue = &Issue{ID: id, URL: d.issueURLs[id]}
+			d.issues[id] = issue
+			d.issuePackages[id] = make(lib.StringSet)
+		}
+		issue.Commits = append(issue.Commits, commit.SHA)
+		for _, pkg := range commit.RelevantPackages {
+			d.issuePackages[id].Add(pkg.ImportPath)
+		}
+	}
+}
+
+// determineIssues orders the issues referenced by relevant commits by ID.
+func (d *diff) determineIssues() {
+	for id, issue := range d.issues {
+		issue.Packages = d.issuePackages[id].Slice()
+		sort.Strings(issue.Packages)
+		d.summary.Issues = append(d.summary.Issues, issue)
+	}
+	sort.Slice(d.summary.Issues, func(i, j int) bool {
+		return d.summary.Issues[i].ID < d.summary.Issues[j].ID
+	})
+}
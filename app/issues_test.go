@@ -0,0 +1,101 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestFindIssues(t *testing.T) {
+	testCases := []struct {
+		name         string
+		rules        []*IssueRule
+		message      string
+		expected     []string
+		expectedURLs map[string]string
+	}{
+		{
+			name:         "default rules",
+			message:      "fix: PROJ-12 and ABC2-3, see #45 and PROJ-12 again; not proj-1 or X-1",
+			expected:     []string{"PROJ-12", "ABC2-3", "#45"},
+			expectedURLs: map[string]string{},
+		},
+		{
+			name:         "default rules ignore standards",
+			message:      "fix: decode UTF-8 and SHA-256 sums, ISO-8601 dates, RFC-3339, ISO-8859-1 and CVE-2021-44228 for AB-1",
+			expected:     []string{"AB-1"},
+			expectedURLs: map[string]string{},
+		},
+		{
+			name:         "default rules need two letters",
+			message:      "fix: A1-2, X-1 and 1A-2 are not issues",
+			expected:     nil,
+			expectedURLs: map[string]string{},
+		},
+		{
+			name:         "custom exclude",
+			rules:        []*IssueRule{{Pattern: `\b[A-Z]+-\d+\b`, Exclude: `^TMP-`}},
+			message:      "fix: TMP-1 is not PROJ-2",
+			expected:     []string{"PROJ-2"},
+			expectedURLs: map[string]string{},
+		},
+		{
+			name:         "no references",
+			message:      "chore: tidy up",
+			expected:     nil,
+			expectedURLs: map[string]string{},
+		},
+		{
+			name: "custom rules",
+			rules: []*IssueRule{
+				{Pattern: `\b(OPS|SEC)-(\d+)\b`, URL: "https://issues.example.com/browse/$0?project=$1&id=$2"},
+				{Pattern: `GH-(\d+)`, URL: "https://github.com/org/repo/issues/${1}"},
+				{Pattern: `INC\d+`},
+			},
+			message:  "fix: OPS-7 and GH-9, incident INC42",
+			expected: []string{"OPS-7", "GH-9", "INC42"},
+			expectedURLs: map[string]string{
+				"OPS-7": "https://issues.example.com/browse/OPS-7?project=OPS&id=7",
+				"GH-9":  "https://github.com/org/repo/issues/9",
+			},
+		},
+		{
+			name:         "empty matches",
+			rules:        []*IssueRule{{Pattern: `\bT\d*`}},
+			message:      "T1, T and Tx",
+			expected:     []string{"T1", "T"},
+			expectedURLs: map[string]string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("case=%s", tc.name), func(t *testing.T) {
+			matchers, err := newIssueMatchers(&Config{Issues: tc.rules})
+			if err != nil {
+				t.Fatal(err)
+			}
+			d := &diff{issueMatchers: matchers, issueURLs: make(map[string]string)}
+
+			if actual := d.findIssues(tc.message); !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("Expected %v but got %v", tc.expected, actual)
+			}
+			if !reflect.DeepEqual(tc.expectedURLs, d.issueURLs) {
+				t.Fatalf("Expected URLs %v but got %v", tc.expectedURLs, d.issueURLs)
+			}
+		})
+	}
+}
+
+func TestNewIssueMatchersErrors(t *testing.T) {
+	for _, pattern := range []string{`\d*`, `(PROJ-\d+)?`, `x|`, `[`} {
+		t.Run(fmt.Sprintf("pattern=%s", pattern), func(t *testing.T) {
+			if _, err := newIssueMatchers(&Config{Issues: []*IssueRule{{Pattern: pattern}}}); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+
+	if _, err := newIssueMatchers(&Config{Issues: []*IssueRule{{Pattern: `PROJ-\d+`, Exclude: `[`}}}); err == nil {
+		t.Fatal("Expected an error for an invalid exclude pattern")
+	}
+}
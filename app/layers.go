@@ -0,0 +1,258 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alecholmes/tdiff/importer"
+	"github.com/alecholmes/tdiff/lib"
+)
+
+// Layer is an architectural layer of packages. Layers are ordered from the top, and packages may only
+// import packages in their own layer or in layers below it. A package belongs to the first layer it matches.
+//
+// Patterns are package patterns, as with DependencyRule.
+type Layer struct {
+	Name     string   `json:"name"`
+	Packages []string `json:"packages"` // Patterns of packages in the layer
+}
+
+// LayerViolation is an import of a package in a higher layer.
+type LayerViolation struct {
+	Package      string `json:"package"`        // Importing package
+	PackageLayer string `json:"packageLayer"`   // Layer of the importing package
+	Import       string `json:"import"`         // Imported package
+	ImportLayer  string `json:"importLayer"`    // Layer of the imported package, above the importing package's
+	File         string `json:"file,omitempty"` // File of the import, relative to the root of the Git repository
+	Line         int    `json:"line,omitempty"` // Line of the import in the file
+}
+
+// DirectoryCycle is a cycle of imports between directories of the Git repository, ignoring test imports.
+// Directories are the leading directories of package directories, up to the config's cycle depth.
+type DirectoryCycle struct {
+	Directories []string           `json:"directories"` // Sorted directories whose packages import each other in a cycle
+	Chain       []string           `json:"chain"`       // Shortest cycle from the first directory back to itself
+	Imports     []*DirectoryImport `json:"imports"`     // An import between packages for each step of the chain
+}
+
+// DirectoryImport is an import of a package by a package in another directory.
+type DirectoryImport struct {
+	From string `json:"from"`           // Importing package
+	To   string `json:"to"`             // Imported package
+	File string `json:"file,omitempty"` // File of the import, relative to the root of the Git repository
+	Line int    `json:"line,omitempty"` // Line of the import in the file
+}
+
+// validateLayers returns an error if the config's layers are not well formed.
+func (c *Config) validateLayers() error {
+	names := make(lib.StringSet)
+	for _, layer := range c.Layers {
+		if len(layer.Name) == 0 {
+			return fmt.Errorf("Layers must have a name")
+		}
+		if names.Contains(layer.Name) {
+			return fmt.Errorf("Layer `%s` is declared more than once", layer.Name)
+		}
+		names.Add(layer.Name)
+	}
+	if c.CycleDepth < 0 {
+		return fmt.Errorf("Cycle depth must not be negative")
+	}
+
+	return nil
+}
+
+// determineLayerViolations finds imports of packages in higher layers that the changes introduced:
+// violations in the graph at HEAD that are not violations in the old graph.
+func (d *diff) determineLayerViolations(oldGraph, newGraph *importer.PackageGraph) error {
+	d.summary.LayerViolations = []*LayerViolation{}
+	if len(d.config.Layers) == 0 {
+		return nil
+	}
+
+	oldViolations := make(lib.StringSet)
+	for _, violation := range d.layerViolations(oldGraph) {
+		oldViolations.Add(violation.Package + "\x00" + violation.Import)
+	}
+
+	for _, violation := range d.layerViolations(newGraph) {
+		if oldViolations.Contains(violation.Package + "\x00" + violation.Import) {
+			continue
+		}
+		var err error
+		if violation.File, violation.Line, err = d.importSite(violation.Package, violation.Import); err != nil {
+			return err
+		}
+		d.summary.LayerViolations = append(d.summary.LayerViolations, violation)
+	}
+
+	return nil
+}
+
+// layerViolations returns the imports of packages in higher layers in a graph, ordered by package and import.
+func (d *diff) layerViolations(graph *importer.PackageGraph) []*LayerViolation {
+	layerOf := func(importPath string) int {
+		for i, layer := range d.config.Layers {
+			for _, pattern := range layer.Packages {
+				if matchPackagePattern(pattern, importPath, d.packagePrefix) {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+
+	names := make([]string, 0, len(graph.Packages))
+	for name := range graph.Packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var violations []*LayerViolation
+	for _, name := range names {
+		packageLayer := layerOf(name)
+		if packageLayer < 0 {
+			continue
+		}
+		for _, importPath := range productionImports(graph.Packages[name]) {
+			if importLayer := layerOf(importPath); importLayer >= 0 && importLayer < packageLayer {
+				violations = append(violations, &LayerViolation{
+					Package:      name,
+					PackageLayer: d.config.Layers[packageLayer].Name,
+					Import:       importPath,
+					ImportLayer:  d.config.Layers[importLayer].Name,
+				})
+			}
+		}
+	}
+
+	return violations
+}
+
+// determineDirectoryCycles finds cycles of imports between directories that the changes introduced:
+// cycles in the graph at HEAD whose directories are not all in one cycle in the old graph.
+func (d *diff) determineDirectoryCycles(oldGraph, newGraph *importer.PackageGraph) error {
+	d.summary.Cycles = []*DirectoryCycle{}
+	if d.config.CycleDepth == 0 {
+		return nil
+	}
+
+	oldComponents := make(map[string]int)
+	for i, cycle := range d.directoryCycles(oldGraph) {
+		for _, dir := range cycle.Directories {
+			oldComponents[dir] = i
+		}
+	}
+
+	for _, cycle := range d.directoryCycles(newGraph) {
+		// A cycle is new unless all of its directories were already in the same cycle.
+		component, existed := oldComponents[cycle.Directories[0]]
+		for _, dir := range cycle.Directories[1:] {
+			if oldComponent, ok := oldComponents[dir]; !ok || oldComponent != component {
+				existed = false
+			}
+		}
+		if existed {
+			continue
+		}
+
+		for _, dirImport := range cycle.Imports {
+			var err error
+			if dirImport.File, dirImport.Line, err = d.importSite(dirImport.From, dirImport.To); err != nil {
+				return err
+			}
+		}
+		d.summary.Cycles = append(d.summary.Cycles, cycle)
+	}
+
+	return nil
+}
+
+// directoryCycles returns the cycles of imports between directories of packages in the Git repository in a graph,
+// one for each set of directories that import each other, ordered by their first directory.
+func (d *diff) directoryCycles(graph *importer.PackageGraph) []*DirectoryCycle {
+	directoryOf := func(importPath string) string {
+		parts := strings.Split(d.packageDir(importPath), "/")
+		if len(parts) > d.config.CycleDepth {
+			parts = parts[:d.config.CycleDepth]
+		}
+		return strings.Join(parts, "/")
+	}
+
+	names := make([]string, 0, len(graph.Packages))
+	for name := range graph.Packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// The smallest import between packages of each pair of directories is kept to explain the cycle.
+	edges := make(map[string][]string)
+	imports := make(map[[2]string]*DirectoryImport)
+	for _, name := range names {
+		if !d.inRepo(name) {
+			continue
+		}
+		from := directoryOf(name)
+		for _, importPath := range productionImports(graph.Packages[name]) {
+			if !d.inRepo(importPath) {
+				continue
+			}
+			to := directoryOf(importPath)
+			if from == to {
+				continue
+			}
+			if _, ok := imports[[2]string{from, to}]; !ok {
+				imports[[2]string{from, to}] = &DirectoryImport{From: name, To: importPath}
+				edges[from] = append(edges[from], to)
+			}
+		}
+	}
+	for from := range edges {
+		sort.Strings(edges[from])
+	}
+
+	var cycles []*DirectoryCycle
+	for _, component := range lib.StronglyConnectedComponents(edges) {
+		if len(component) < 2 {
+			continue
+		}
+		members := make(lib.StringSet)
+		members.Add(component...)
+
+		// Search breadth first within the component for the shortest path back to the first directory.
+		start := component[0]
+		parents := make(map[string]string)
+		queue := []string{start}
+		var last string
+		for len(queue) > 0 && len(last) == 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, to := range edges[current] {
+				if to == start {
+					last = current
+					break
+				}
+				if _, ok := parents[to]; !ok && members.Contains(to) {
+					parents[to] = current
+					queue = append(queue, to)
+				}
+			}
+		}
+
+		chain := []string{start}
+		for current := last; current != start; current = parents[current] {
+			chain = append([]string{current}, chain...)
+		}
+		chain = append([]string{start}, chain...)
+
+		cycle := &DirectoryCycle{Directories: component, Chain: chain}
+		for i := 1; i < len(chain); i++ {
+			dirImport := *imports[[2]string{chain[i-1], chain[i]}]
+			cycle.Imports = append(cycle.Imports, &dirImport)
+		}
+		cycles = append(cycles, cycle)
+	}
+
+	return cycles
+}
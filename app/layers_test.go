@@ -0,0 +1,188 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/alecholmes/tdiff/importer"
+)
+
+func TestLayerViolations(t *testing.T) {
+	graph := &importer.PackageGraph{Packages: make(map[string]*importer.Package)}
+	addFakePackage(graph, "repo/svc", "repo/a", "repo/util", "fmt")
+	addFakePackage(graph, "repo/svc/core", "repo/a")
+	addFakePackage(graph, "repo/a", "repo/util", "repo/svc/core", "repo/other")
+	addFakePackage(graph, "repo/util", "repo/a", "fmt")
+	addFakePackage(graph, "repo/other", "repo/svc")
+	addFakePackage(graph, "fmt")
+
+	d := &diff{
+		packagePrefix: "repo",
+		config: &Config{Layers: []*Layer{
+			{Name: "app", Packages: []string{"./svc/..."}},
+			// repo/svc/core is in the app layer, which it matches first.
+			{Name: "domain", Packages: []string{"./svc/core", "./a/..."}},
+			{Name: "lib", Packages: []string{"./util/...", "fmt"}},
+		}},
+	}
+
+	// Importing packages in the same layer, lower layers or no layer is allowed.
+	expected := []*LayerViolation{
+		{Package: "repo/a", PackageLayer: "domain", Import: "repo/svc/core", ImportLayer: "app"},
+		{Package: "repo/util", PackageLayer: "lib", Import: "repo/a", ImportLayer: "domain"},
+	}
+	if actual := d.layerViolations(graph); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %+v but got %+v", expected, actual)
+	}
+}
+
+func TestValidateLayers(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config *Config
+		valid  bool
+	}{
+		{name: "empty", config: &Config{}, valid: true},
+		{name: "layers", config: &Config{Layers: []*Layer{{Name: "a"}, {Name: "b"}}, CycleDepth: 2}, valid: true},
+		{name: "no name", config: &Config{Layers: []*Layer{{Packages: []string{"./a"}}}}},
+		{name: "duplicate name", config: &Config{Layers: []*Layer{{Name: "a"}, {Name: "a"}}}},
+		{name: "negative depth", config: &Config{CycleDepth: -1}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("case=%s", tc.name), func(t *testing.T) {
+			if err := tc.config.validateLayers(); (err == nil) != tc.valid {
+				t.Fatalf("Expected valid=%v but got error %v", tc.valid, err)
+			}
+		})
+	}
+}
+
+// testCycleGraph returns a graph with imports between these directories:
+//
+//	a/p -> b/p -> c -> a/q, and a/p -> b/q and c -> b/q, so the shortest cycle from a is a > b > c > a at depth 1
+//	x/one -> x/two -> x/one, which are one directory at depth 1
+//	repo -> a, and packages outside the repository importing each other
+func testCycleGraph() *importer.PackageGraph {
+	graph := &importer.PackageGraph{Packages: make(map[string]*importer.Package)}
+	addFakePackage(graph, "repo", "repo/a/p", "repo/x/one")
+	addFakePackage(graph, "repo/a/p", "repo/b/q", "repo/b/p")
+	addFakePackage(graph, "repo/b/p", "repo/c")
+	addFakePackage(graph, "repo/b/q")
+	addFakePackage(graph, "repo/c", "repo/a/q", "repo/b/q")
+	addFakePackage(graph, "repo/a/q")
+	addFakePackage(graph, "repo/x/one", "repo/x/two/p")
+	addFakePackage(graph, "repo/x/two/p", "repo/x/two/q")
+	addFakePackage(graph, "repo/x/two/q", "repo/x/one", "other/a")
+	addFakePackage(graph, "other/a", "other/b")
+	addFakePackage(graph, "other/b", "other/a")
+
+	return graph
+}
+
+func TestDirectoryCycles(t *testing.T) {
+	testCases := []struct {
+		depth    int
+		expected []*DirectoryCycle
+	}{
+		{
+			depth: 1,
+			expected: []*DirectoryCycle{
+				{
+					Directories: []string{"a", "b", "c"},
+					Chain:       []string{"a", "b", "c", "a"},
+					Imports: []*DirectoryImport{
+						{From: "repo/a/p", To: "repo/b/p"},
+						{From: "repo/b/p", To: "repo/c"},
+						{From: "repo/c", To: "repo/a/q"},
+					},
+				},
+			},
+		},
+		{
+			// a/p and a/q are different directories, so a > b > c is no longer a cycle.
+			depth: 2,
+			expected: []*DirectoryCycle{
+				{
+					Directories: []string{"x/one", "x/two"},
+					Chain:       []string{"x/one", "x/two", "x/one"},
+					Imports: []*DirectoryImport{
+						{From: "repo/x/one", To: "repo/x/two/p"},
+						{From: "repo/x/two/q", To: "repo/x/one"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("depth=%d", tc.depth), func(t *testing.T) {
+			d := &diff{packagePrefix: "repo", config: &Config{CycleDepth: tc.depth}}
+			if actual := d.directoryCycles(testCycleGraph()); !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("Expected %s but got %s", cycleString(tc.expected), cycleString(actual))
+			}
+		})
+	}
+}
+
+func TestDetermineDirectoryCycles(t *testing.T) {
+	// At depth 1, the old graph has the cycle a > b > c > a.
+	testCases := []struct {
+		name     string
+		imports  map[string][]string
+		expected [][]string
+	}{
+		{
+			name:     "unchanged",
+			expected: nil,
+		},
+		{
+			name:     "cycle within the old cycle",
+			imports:  map[string][]string{"repo/b/q": {"repo/a/q"}},
+			expected: nil,
+		},
+		{
+			name:     "new cycle",
+			imports:  map[string][]string{"repo/x/one": {"repo/d"}, "repo/d": {"repo/x/two/p"}},
+			expected: [][]string{{"d", "x"}},
+		},
+		{
+			name:     "old cycle extended",
+			imports:  map[string][]string{"repo/c": {"repo/a/q", "repo/b/q", "repo/d"}, "repo/d": {"repo/a/p"}},
+			expected: [][]string{{"a", "b", "c", "d"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("case=%s", tc.name), func(t *testing.T) {
+			newGraph := testCycleGraph()
+			for name, imports := range tc.imports {
+				addFakePackage(newGraph, name, imports...)
+			}
+
+			d := &diff{packagePrefix: "repo", config: &Config{CycleDepth: 1}, graph: newGraph}
+			if err := d.determineDirectoryCycles(testCycleGraph(), newGraph); err != nil {
+				t.Fatal(err)
+			}
+			var actual [][]string
+			for _, cycle := range d.summary.Cycles {
+				actual = append(actual, cycle.Directories)
+			}
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("Expected %v but got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func cycleString(cycles []*DirectoryCycle) string {
+	var s string
+	for _, cycle := range cycles {
+		s += fmt.Sprintf("\n%v %v", cycle.Directories, cycle.Chain)
+		for _, dirImport := range cycle.Imports {
+			s += fmt.Sprintf(" %+v", *dirImport)
+		}
+	}
+	return s
+}
@@ -0,0 +1,166 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Release note groupings for MarkdownOptions.GroupBy.
+const (
+	GroupByType    = "type"    // By conventional commit type, e.g. "feat" or "fix"
+	GroupByPackage = "package" // By relevant changed package
+)
+
+// MarkdownOptions control how release notes are rendered.
+type MarkdownOptions struct {
+	GroupBy   string // How commits are grouped, GroupByType or GroupByPackage
+	CommitURL string // Template of links to commits, where {sha} is replaced with the commit SHA. No links if empty.
+}
+
+// releaseNoteSections are the headings of commits grouped by conventional commit type, in order.
+// Breaking commits are listed in their own section rather than by type.
+var releaseNoteSections = []struct {
+	heading string
+	types   []string
+}{
+	{"Breaking Changes", nil},
+	{"Features", []string{"feat"}},
+	{"Bug Fixes", []string{"fix"}},
+	{"Performance", []string{"perf"}},
+	{"Refactoring", []string{"refactor"}},
+	{"Documentation", []string{"docs"}},
+	{"Tests", []string{"test"}},
+	{"Maintenance", []string{"build", "chore", "ci", "style"}},
+	{"Other Changes", nil},
+}
+
+// Markdown renders a summary as markdown release notes of the relevant commits.
+// Commits that are in the range but not relevant are listed in a collapsed section, if the
+// summary includes them.
+func Markdown(summary *Summary, opts MarkdownOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Release notes for %s\n\n", summary.RootImportPath)
+	fmt.Fprintf(&buf, "Changes after `%s`: %d commits, %d packages, %d files.\n", summary.SHA, len(summary.Commits), len(summary.Packages), len(summary.Files))
+
+	switch opts.GroupBy {
+	case "", GroupByType:
+		writeTypeSections(&buf, "##", summary.Commits, opts.CommitURL)
+	case GroupByPackage:
+		var other []*Commit
+		for _, pkg := range summary.Packages {
+			var commits []*Commit
+			for _, commit := range summary.Commits {
+				for _, commitPackage := range commit.RelevantPackages {
+					if commitPackage.ImportPath == pkg.ImportPath {
+						commits = append(commits, commit)
+						break
+					}
+				}
+			}
+			writeMarkdownSection(&buf, fmt.Sprintf("## `%s`", pkg.ImportPath), commits, false, opts.CommitURL)
+		}
+		for _, commit := range summary.Commits {
+			if len(commit.RelevantPackages) == 0 {
+				other = append(other, commit)
+			}
+		}
+		writeMarkdownSection(&buf, "## Other Files", other, false, opts.CommitURL)
+	default:
+		return nil, fmt.Errorf("Unknown release note grouping `%s`", opts.GroupBy)
+	}
+
+	if len(summary.Issues) > 0 {
+		buf.WriteString("\n## Issues\n\n")
+		for _, issue := range summary.Issues {
+			id := issue.ID
+			if len(issue.URL) > 0 {
+				id = fmt.Sprintf("[%s](%s)", issue.ID, issue.URL)
+			}
+			var packages []string
+			for _, pkg := range issue.Packages {
+				packages = append(packages, fmt.Sprintf("`%s`", pkg))
+			}
+			if len(packages) == 0 {
+				packages = []string{"no packages"}
+			}
+			fmt.Fprintf(&buf, "- %s: %s (%d commits)\n", id, strings.Join(packages, ", "), len(issue.Commits))
+		}
+	}
+
+	if len(summary.OtherCommits) > 0 {
+		fmt.Fprintf(&buf, "\n<details>\n<summary>%d other commits in range</summary>\n\n", len(summary.OtherCommits))
+		for _, commit := range summary.OtherCommits {
+			writeMarkdownCommit(&buf, commit, false, opts.CommitURL)
+		}
+		buf.WriteString("\n</details>\n")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// releaseNoteHeading returns the heading of the section a commit belongs in when grouped by type.
+func releaseNoteHeading(commit *Commit) string {
+	if commit.Conventional == nil {
+		return "Other Changes"
+	}
+	if commit.Conventional.Breaking {
+		return "Breaking Changes"
+	}
+	for _, section := range releaseNoteSections {
+		for _, commitType := range section.types {
+			if commit.Conventional.Type == commitType {
+				return section.heading
+			}
+		}
+	}
+
+	return "Other Changes"
+}
+
+// writeTypeSections writes commits grouped by conventional commit type, with headings at the given level.
+func writeTypeSections(buf *bytes.Buffer, level string, commits []*Commit, commitURL string) {
+	grouped := make(map[string][]*Commit)
+	for _, commit := range commits {
+		heading := releaseNoteHeading(commit)
+		grouped[heading] = append(grouped[heading], commit)
+	}
+	for _, section := range releaseNoteSections {
+		writeMarkdownSection(buf, level+" "+section.heading, grouped[section.heading], true, commitURL)
+	}
+}
+
+func writeMarkdownSection(buf *bytes.Buffer, heading string, commits []*Commit, byType bool, commitURL string) {
+	if len(commits) == 0 {
+		return
+	}
+
+	fmt.Fprintf(buf, "\n%s\n\n", heading)
+	for _, commit := range commits {
+		writeMarkdownCommit(buf, commit, byType, commitURL)
+	}
+}
+
+// writeMarkdownCommit writes a commit as a list item. When grouped by type, the type is given by
+// the heading so only the scope of conventional commits is kept.
+func writeMarkdownCommit(buf *bytes.Buffer, commit *Commit, byType bool, commitURL string) {
+	description := commit.Description
+	if conventional := commit.Conventional; byType && conventional != nil {
+		description = conventional.Description
+		if len(conventional.Scope) > 0 {
+			description = fmt.Sprintf("**%s:** %s", conventional.Scope, description)
+		}
+	}
+
+	sha := commit.SHA
+	if len(sha) > 7 {
+		sha = sha[:7]
+	}
+
+	ref := fmt.Sprintf("`%s`", sha)
+	if len(commitURL) > 0 {
+		ref = fmt.Sprintf("[`%s`](%s)", sha, strings.Replace(commitURL, "{sha}", commit.SHA, -1))
+	}
+
+	fmt.Fprintf(buf, "- %s (%s)\n", description, ref)
+}
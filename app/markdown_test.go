@@ -0,0 +1,84 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alecholmes/tdiff/lib"
+)
+
+// testReleaseSummary returns a summary with a relevant commit of each kind, newest first.
+func testReleaseSummary() *Summary {
+	libPkg := &Package{ImportPath: "example.com/repo/lib"}
+	utilPkg := &Package{ImportPath: "example.com/repo/util"}
+
+	return &Summary{
+		RootImportPath: "example.com/repo/cmd",
+		SHA:            "v1.2.3",
+		Packages:       []*Package{libPkg, utilPkg},
+		Files:          []string{"lib/lib.go", "schema.sql", "util/util.go"},
+		Commits: []*Commit{
+			{
+				SHA:              "1111111111111111111111111111111111111111",
+				Description:      "fix(util): handle empty input",
+				Conventional:     &lib.ConventionalCommit{Type: "fix", Scope: "util", Description: "handle empty input"},
+				RelevantPackages: []*Package{utilPkg},
+			},
+			{
+				SHA:              "2222222222222222222222222222222222222222",
+				Description:      "Update schema",
+				RelevantPackages: []*Package{},
+			},
+			{
+				SHA:              "3333333333333333333333333333333333333333",
+				Description:      "feat: add lookup",
+				Conventional:     &lib.ConventionalCommit{Type: "feat", Description: "add lookup"},
+				RelevantPackages: []*Package{libPkg, utilPkg},
+			},
+			{
+				SHA:              "4444444444444444444444444444444444444444",
+				Description:      "chore(lib): bump deps",
+				Conventional:     &lib.ConventionalCommit{Type: "chore", Scope: "lib", Description: "bump deps"},
+				RelevantPackages: []*Package{libPkg},
+			},
+			{
+				SHA:              "5555555555555555555555555555555555555555",
+				Description:      "feat(lib)!: take a context PROJ-7",
+				Conventional:     &lib.ConventionalCommit{Type: "feat", Scope: "lib", Breaking: true, Description: "take a context PROJ-7"},
+				RelevantPackages: []*Package{libPkg},
+				Issues:           []string{"PROJ-7"},
+			},
+		},
+		OtherCommits: []*Commit{
+			{SHA: "6666666666666666666666666666666666666666", Description: "docs: update readme"},
+		},
+		Issues: []*Issue{
+			{ID: "PROJ-7", URL: "https://issues.example.com/PROJ-7", Commits: []string{"5555555555555555555555555555555555555555"}, Packages: []string{"example.com/repo/lib"}},
+		},
+	}
+}
+
+func TestMarkdownGolden(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts MarkdownOptions
+	}{
+		{name: "type", opts: MarkdownOptions{GroupBy: GroupByType}},
+		{name: "package", opts: MarkdownOptions{GroupBy: GroupByPackage}},
+		{name: "url", opts: MarkdownOptions{CommitURL: "https://git.example.com/repo/commit/{sha}?full={sha}"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("case=%s", tc.name), func(t *testing.T) {
+			actual, err := Markdown(testReleaseSummary(), tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, fmt.Sprintf("markdown_%s.golden.md", tc.name), actual)
+		})
+	}
+
+	if _, err := Markdown(testReleaseSummary(), MarkdownOptions{GroupBy: "author"}); err == nil {
+		t.Fatal("Expected an error for an unknown grouping")
+	}
+}
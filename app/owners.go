@@ -0,0 +1,87 @@
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/alecholmes/tdiff/lib"
+)
+
+// Owner describes the relevant changes to code owned by one owner, as declared in CODEOWNERS.
+type Owner struct {
+	Name     string   `json:"name"`     // Owner as written in CODEOWNERS, e.g. "@org/team"
+	Packages []string `json:"packages"` // Relevant changed packages with files owned by the owner
+	Files    []string `json:"files"`    // Relevant changed files owned by the owner
+}
+
+// loadCodeOwners reads the CODEOWNERS file of a Git repository.
+// Nil is returned if the repository has no CODEOWNERS file.
+func loadCodeOwners(gitRoot string, logger Logger) (*lib.CodeOwners, error) {
+	for _, file := range lib.CodeOwnersFiles {
+		body, err := ioutil.ReadFile(filepath.Join(gitRoot, file))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		logger("Using code owners file: %s", file)
+		return lib.ParseCodeOwners(body), nil
+	}
+
+	return nil, nil
+}
+
+// determineOwners annotates relevant files and packages with their owners, and aggregates
+// the relevant changes by owner.
+func (d *diff) determineOwners(logger Logger) error {
+	codeOwners, err := loadCodeOwners(d.git.RootDir, logger)
+	if err != nil || codeOwners == nil {
+		return err
+	}
+
+	owners := make(map[string]*Owner)
+	ownerOf := func(name string) *Owner {
+		if _, ok := owners[name]; !ok {
+			owners[name] = &Owner{Name: name, Packages: []string{}, Files: []string{}}
+		}
+		return owners[name]
+	}
+
+	d.summary.FileOwners = make(map[string][]string)
+	for _, file := range d.summary.Files {
+		if fileOwners := codeOwners.Owners(file); len(fileOwners) > 0 {
+			d.summary.FileOwners[file] = fileOwners
+			for _, name := range fileOwners {
+				owner := ownerOf(name)
+				owner.Files = append(owner.Files, file)
+			}
+		}
+	}
+
+	for _, pkg := range d.summary.Packages {
+		packageOwners := make(lib.StringSet)
+		for _, file := range pkg.Files {
+			packageOwners.Add(codeOwners.Owners(file)...)
+		}
+		pkg.Owners = packageOwners.Slice()
+		sort.Strings(pkg.Owners)
+		for _, name := range pkg.Owners {
+			owner := ownerOf(name)
+			owner.Packages = append(owner.Packages, pkg.ImportPath)
+		}
+	}
+
+	for _, owner := range owners {
+		sort.Strings(owner.Packages)
+		sort.Strings(owner.Files)
+		d.summary.Owners = append(d.summary.Owners, owner)
+	}
+	sort.Slice(d.summary.Owners, func(i, j int) bool {
+		return d.summary.Owners[i].Name < d.summary.Owners[j].Name
+	})
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/alecholmes/tdiff/lib"
+)
+
+func TestDetermineOwners(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tdiff-owners")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.MkdirAll(filepath.Join(dir, ".github"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	codeOwners := []byte(`
+/lib/       @org/lib
+/lib/b/*.go @org/lib alice@example.com
+/util/      @org/util
+*.proto     @org/api
+`)
+	if err := ioutil.WriteFile(filepath.Join(dir, ".github", "CODEOWNERS"), codeOwners, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &diff{
+		git: &lib.Git{RootDir: dir},
+		summary: Summary{
+			Packages: []*Package{
+				{ImportPath: "repo/lib/a", Files: []string{"lib/a/a.go"}},
+				{ImportPath: "repo/lib/b", Files: []string{"lib/b/b.go", "lib/b/b.proto"}},
+				{ImportPath: "repo/other", Files: []string{"other/other.go"}},
+			},
+			Files: []string{"docs/README.md", "lib/a/a.go", "lib/b/b.go", "lib/b/b.proto", "other/other.go", "util/util.sh"},
+		},
+	}
+	if err := d.determineOwners(NoLogging); err != nil {
+		t.Fatal(err)
+	}
+
+	// Files with no matching rule have no owners.
+	expectedFileOwners := map[string][]string{
+		"lib/a/a.go":    {"@org/lib"},
+		"lib/b/b.go":    {"@org/lib", "alice@example.com"},
+		"lib/b/b.proto": {"@org/api"},
+		"util/util.sh":  {"@org/util"},
+	}
+	if !reflect.DeepEqual(expectedFileOwners, d.summary.FileOwners) {
+		t.Fatalf("Expected file owners %v but got %v", expectedFileOwners, d.summary.FileOwners)
+	}
+
+	expectedPackageOwners := map[string][]string{
+		"repo/lib/a": {"@org/lib"},
+		"repo/lib/b": {"@org/api", "@org/lib", "alice@example.com"},
+		"repo/other": {},
+	}
+	for _, pkg := range d.summary.Packages {
+		if !reflect.DeepEqual(expectedPackageOwners[pkg.ImportPath], pkg.Owners) {
+			t.Fatalf("Expected owners of %s %v but got %v", pkg.ImportPath, expectedPackageOwners[pkg.ImportPath], pkg.Owners)
+		}
+	}
+
+	// Owners without relevant packages, like owners of artifacts, have an empty list of packages.
+	expectedOwners := []*Owner{
+		{Name: "@org/api", Packages: []string{"repo/lib/b"}, Files: []string{"lib/b/b.proto"}},
+		{Name: "@org/lib", Packages: []string{"repo/lib/a", "repo/lib/b"}, Files: []string{"lib/a/a.go", "lib/b/b.go"}},
+		{Name: "@org/util", Packages: []string{}, Files: []string{"util/util.sh"}},
+		{Name: "alice@example.com", Packages: []string{"repo/lib/b"}, Files: []string{"lib/b/b.go"}},
+	}
+	if !reflect.DeepEqual(expectedOwners, d.summary.Owners) {
+		actual, _ := json.Marshal(d.summary.Owners)
+		t.Fatalf("Expected owners %+v but got %s", expectedOwners, actual)
+	}
+}
+
+func TestDetermineOwnersWithoutCodeOwners(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tdiff-owners")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	d := &diff{
+		git: &lib.Git{RootDir: dir},
+		summary: Summary{
+			Packages: []*Package{{ImportPath: "repo/lib", Files: []string{"lib/lib.go"}}},
+			Files:    []string{"lib/lib.go"},
+		},
+	}
+	if err := d.determineOwners(NoLogging); err != nil {
+		t.Fatal(err)
+	}
+	if d.summary.FileOwners != nil || d.summary.Owners != nil || d.summary.Packages[0].Owners != nil {
+		t.Fatalf("Expected no owners but got %v, %v and %v", d.summary.FileOwners, d.summary.Owners, d.summary.Packages[0].Owners)
+	}
+}
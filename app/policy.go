@@ -0,0 +1,396 @@
+package app
+
+import (
+	"fmt"
+	"go/build"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alecholmes/tdiff/importer"
+	"github.com/alecholmes/tdiff/lib"
+	"github.com/alecholmes/tdiff/source"
+)
+
+// Policy declares a rule about which packages in the graph of the root package may import which.
+// Only production imports are considered; test imports are ignored.
+//
+// A policy either forbids packages from importing packages, directly or transitively:
+//
+//	{"name": "no-experimental", "packages": ["./services/payments/..."], "forbid": ["./internal/experimental/..."]}
+//
+// or allows only some packages to directly import packages:
+//
+//	{"name": "db-access", "imports": ["database/sql"], "allow": ["./lib/db"]}
+//
+// Patterns are package patterns, as with DependencyRule.
+type Policy struct {
+	Name     string   `json:"name"`
+	Packages []string `json:"packages"` // Patterns of packages that must not import forbidden packages; all packages if empty
+	Forbid   []string `json:"forbid"`   // Patterns of packages that the packages must not import
+	Imports  []string `json:"imports"`  // Patterns of packages that only allowed packages may import
+	Allow    []string `json:"allow"`    // Patterns of packages allowed to import the imports
+}
+
+// PolicyViolation is an import of a package that a policy does not allow.
+type PolicyViolation struct {
+	Policy  string   `json:"policy"`         // Name of the violated policy
+	Package string   `json:"package"`        // Package violating the policy
+	Import  string   `json:"import"`         // Package that the package must not import
+	Chain   []string `json:"chain"`          // Shortest import chain from the package to the import
+	File    string   `json:"file,omitempty"` // File of the package importing the next package of the chain, relative to the root of the Git repository
+	Line    int      `json:"line,omitempty"` // Line of the import in the file
+}
+
+// validate returns an error if the policy is not well formed.
+func (p *Policy) validate() error {
+	if len(p.Name) == 0 {
+		return fmt.Errorf("Policies must have a name")
+	}
+	if (len(p.Forbid) > 0) == (len(p.Imports) > 0) {
+		return fmt.Errorf("Policy `%s` must have either forbid or imports patterns", p.Name)
+	}
+	if len(p.Forbid) > 0 && len(p.Allow) > 0 {
+		return fmt.Errorf("Policy `%s` has allow patterns, which only apply to imports patterns", p.Name)
+	}
+
+	return nil
+}
+
+// checkPolicies finds violations of the config's policies and layers, and directory cycles, that the
+// changes introduced: those in the graph at HEAD that are not in the graph at the given SHA. Only packages
+// reachable from the root package through production imports are checked.
+func (d *diff) checkPolicies() error {
+	for _, policy := range d.config.Policies {
+		if err := policy.validate(); err != nil {
+			return err
+		}
+	}
+	if err := d.config.validateLayers(); err != nil {
+		return err
+	}
+
+	newGraph := productionGraph(d.graph.WithoutTests(), d.summary.RootImportPath)
+	var oldGraph *importer.PackageGraph
+	if len(d.config.Policies) > 0 || len(d.config.Layers) > 0 || d.config.CycleDepth > 0 {
+		var err error
+		if oldGraph, err = d.productionGraphAt(d.summary.SHA); err != nil {
+			return err
+		}
+	}
+
+	if err := d.determinePolicyViolations(oldGraph, newGraph); err != nil {
+		return err
+	}
+	if err := d.determineLayerViolations(oldGraph, newGraph); err != nil {
+		return err
+	}
+
+	return d.determineDirectoryCycles(oldGraph, newGraph)
+}
+
+// determinePolicyViolations finds violations of the config's policies in the graph at HEAD that are not
+// violations in the old graph.
+func (d *diff) determinePolicyViolations(oldGraph, newGraph *importer.PackageGraph) error {
+	d.summary.Violations = []*PolicyViolation{}
+	if len(d.config.Policies) == 0 {
+		return nil
+	}
+
+	oldViolations := make(lib.StringSet)
+	for _, violation := range d.policyViolations(oldGraph) {
+		oldViolations.Add(violation.key())
+	}
+
+	for _, violation := range d.policyViolations(newGraph) {
+		if oldViolations.Contains(violation.key()) {
+			continue
+		}
+		var err error
+		if violation.File, violation.Line, err = d.importSite(violation.Package, violation.Chain[1]); err != nil {
+			return err
+		}
+		d.summary.Violations = append(d.summary.Violations, violation)
+	}
+
+	return nil
+}
+
+// key identifies a violation independently of its import chain.
+func (v *PolicyViolation) key() string {
+	return strings.Join([]string{v.Policy, v.Package, v.Import}, "\x00")
+}
+
+// policyViolations returns the violations of the config's policies in a graph, ordered by policy, package and import.
+func (d *diff) policyViolations(graph *importer.PackageGraph) []*PolicyViolation {
+	names := make([]string, 0, len(graph.Packages))
+	for name := range graph.Packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	matches := func(patterns []string, importPath string) bool {
+		for _, pattern := range patterns {
+			if matchPackagePattern(pattern, importPath, d.packagePrefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var violations []*PolicyViolation
+	for _, policy := range d.config.Policies {
+		for _, name := range names {
+			if len(policy.Imports) > 0 {
+				if matches(policy.Allow, name) || matches(policy.Imports, name) {
+					continue
+				}
+				for _, importPath := range productionImports(graph.Packages[name]) {
+					if matches(policy.Imports, importPath) {
+						violations = append(violations, &PolicyViolation{
+							Policy:  policy.Name,
+							Package: name,
+							Import:  importPath,
+							Chain:   []string{name, importPath},
+						})
+					}
+				}
+				continue
+			}
+
+			if (len(policy.Packages) > 0 && !matches(policy.Packages, name)) || matches(policy.Forbid, name) {
+				continue
+			}
+
+			// Search breadth first, stopping at forbidden packages, so each forbidden package where a
+			// chain first enters the forbidden packages is found with the shortest chain to it.
+			parents := map[string]string{name: ""}
+			var forbidden []string
+			queue := []string{name}
+			for len(queue) > 0 {
+				current := queue[0]
+				queue = queue[1:]
+				pkg, ok := graph.Packages[current]
+				if !ok {
+					continue
+				}
+				for _, importPath := range productionImports(pkg) {
+					if _, ok := parents[importPath]; ok {
+						continue
+					}
+					parents[importPath] = current
+					if matches(policy.Forbid, importPath) {
+						forbidden = append(forbidden, importPath)
+					} else {
+						queue = append(queue, importPath)
+					}
+				}
+			}
+
+			sort.Strings(forbidden)
+			for _, importPath := range forbidden {
+				var chain []string
+				for current := importPath; len(current) > 0; current = parents[current] {
+					chain = append([]string{current}, chain...)
+				}
+				violations = append(violations, &PolicyViolation{
+					Policy:  policy.Name,
+					Package: name,
+					Import:  importPath,
+					Chain:   chain,
+				})
+			}
+		}
+	}
+
+	return violations
+}
+
+// productionImports returns the sorted, unique vendored import paths of a package in a graph without
+// test imports, other than "C".
+func productionImports(pkg *importer.Package) []string {
+	importSet := make(lib.StringSet)
+	for _, importPath := range pkg.AllImports(true) {
+		if importPath != "C" {
+			importSet.Add(importPath)
+		}
+	}
+
+	imports := importSet.Slice()
+	sort.Strings(imports)
+
+	return imports
+}
+
+// importSite returns the file, relative to the root of the Git repository, and line where one package
+// imports another in production code, or runs it with a //go:generate directive. An empty file is returned if the package is outside the repository.
+func (d *diff) importSite(from, to string) (string, int, error) {
+	sites, err := d.graph.Packages[from].ImportSites(to)
+	if err != nil {
+		return "", 0, err
+	}
+
+	for _, site := range sites {
+		if site.Kind == importer.ImportTest {
+			continue
+		}
+		file, err := filepath.Rel(d.git.RootDir, site.File)
+		if err != nil || strings.HasPrefix(file, "..") {
+			return "", 0, nil
+		}
+		return filepath.ToSlash(file), site.Line, nil
+	}
+
+	return "", 0, nil
+}
+
+// productionGraph returns the packages of a graph without test imports that are reachable from the root package.
+func productionGraph(graph *importer.PackageGraph, root string) *importer.PackageGraph {
+	reachable := &importer.PackageGraph{Packages: make(map[string]*importer.Package)}
+	for queue := []string{root}; len(queue) > 0; queue = queue[1:] {
+		pkg, ok := graph.Packages[queue[0]]
+		if _, visited := reachable.Packages[queue[0]]; visited || !ok {
+			continue
+		}
+		reachable.Packages[queue[0]] = pkg
+		queue = append(queue, productionImports(pkg)...)
+	}
+
+	return reachable
+}
+
+// productionGraphAt returns the graph of the root package without test imports as of the commit of the given SHA.
+// If packages run by //go:generate directives are imports, they are found in the directives as of the commit.
+func (d *diff) productionGraphAt(sha string) (*importer.PackageGraph, error) {
+	var generators func(pkg string) ([]string, error)
+	if d.generate {
+		generators = func(pkg string) ([]string, error) {
+			return d.generatorImportsAt(pkg, sha)
+		}
+	}
+
+	return d.productionGraphFrom(func(pkg string) (map[string][]byte, error) {
+		return d.packageSources(pkg, sha)
+	}, generators)
+}
+
+// generatorImportsAt returns the import paths, as written, of the packages run by the //go:generate directives
+// of a package as of the commit of the given SHA. Like the importer, directives in all Go files of the package
+// directory are considered, including test files and files excluded by build constraints.
+func (d *diff) generatorImportsAt(pkg, sha string) ([]string, error) {
+	dir := d.packageDir(pkg)
+	files, err := d.git.ListFiles(sha, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var importPaths []string
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".go") {
+			continue
+		}
+		body, err := d.git.FileAt(sha, file)
+		if err != nil {
+			return nil, err
+		}
+		directives, err := importer.ParseGenerateDirectives(file, body)
+		if err != nil {
+			return nil, err
+		}
+		for _, directive := range directives {
+			importPaths = append(importPaths, directive.RunImports(pkg, func(name string) ([]byte, error) {
+				return d.git.FileAt(sha, path.Join(dir, name))
+			})...)
+		}
+	}
+
+	return importPaths, nil
+}
+
+// productionGraphFrom returns the graph of the packages reachable from the root package through production imports,
+// where packages in the Git repository import what their given sources import, and packages outside it are assumed
+// to be as in the graph at HEAD. Packages outside the repository that are not in the graph at HEAD have no imports.
+// If generators is set, packages in the repository also import the packages it returns, as written, as generators.
+func (d *diff) productionGraphFrom(sources func(pkg string) (map[string][]byte, error), generators func(pkg string) ([]string, error)) (*importer.PackageGraph, error) {
+	headGraph := d.graph.WithoutTests()
+	graph := &importer.PackageGraph{Packages: make(map[string]*importer.Package)}
+
+	queue := []string{d.summary.RootImportPath}
+	for ; len(queue) > 0; queue = queue[1:] {
+		name := queue[0]
+		if _, ok := graph.Packages[name]; ok {
+			continue
+		}
+		headPkg := headGraph.Packages[name]
+
+		if !d.inRepo(name) {
+			if headPkg == nil {
+				headPkg = &importer.Package{
+					Package:             &build.Package{ImportPath: name},
+					ImportVendoredPaths: make(map[string]string),
+				}
+			}
+			graph.Packages[name] = headPkg
+			queue = append(queue, productionImports(headPkg)...)
+			continue
+		}
+
+		files, err := sources(name)
+		if err != nil {
+			return nil, err
+		}
+		importSet := make(lib.StringSet)
+		for _, body := range files {
+			fileImports, err := source.Imports(body)
+			if err != nil {
+				// Unparseable files don't contribute imports
+				continue
+			}
+			for _, fileImport := range fileImports {
+				importSet.Add(fileImport.Path)
+			}
+		}
+
+		pkg := &importer.Package{
+			Package:             &build.Package{ImportPath: name, Imports: importSet.Slice()},
+			ImportVendoredPaths: make(map[string]string),
+		}
+		sort.Strings(pkg.Imports)
+		// Imports are resolved to vendored packages as they are at HEAD.
+		vendored := func(importPath string) string {
+			if headPkg != nil {
+				if headVendored, ok := headPkg.ImportVendoredPaths[importPath]; ok {
+					return headVendored
+				}
+			}
+			return importPath
+		}
+		for _, importPath := range pkg.Imports {
+			pkg.ImportVendoredPaths[importPath] = vendored(importPath)
+		}
+
+		if generators != nil {
+			generatorPaths, err := generators(name)
+			if err != nil {
+				return nil, err
+			}
+			generatorSet := make(lib.StringSet)
+			for _, importPath := range generatorPaths {
+				generatorSet.Add(vendored(importPath))
+			}
+			pkg.GenerateImports = generatorSet.Slice()
+			sort.Strings(pkg.GenerateImports)
+		}
+
+		graph.Packages[name] = pkg
+		queue = append(queue, productionImports(pkg)...)
+	}
+
+	return graph, nil
+}
+
+// inRepo returns true if a package is in the Git repository.
+func (d *diff) inRepo(importPath string) bool {
+	return importPath == d.packagePrefix || strings.HasPrefix(importPath, d.packagePrefix+"/")
+}
@@ -0,0 +1,264 @@
+package app
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"github.com/alecholmes/tdiff/importer"
+	"github.com/alecholmes/tdiff/lib"
+)
+
+// testPolicyGraph returns this graph:
+//
+//	repo/cmd -> [repo/svc, repo/lib/db, database/sql]
+//	repo/svc -> [repo/mid]
+//	repo/mid -> [repo/exp/a]
+//	repo/exp/a -> [repo/exp/b]
+//	repo/lib/db -> [database/sql]
+func testPolicyGraph() *importer.PackageGraph {
+	graph := &importer.PackageGraph{Packages: make(map[string]*importer.Package)}
+	addFakePackage(graph, "repo/cmd", "repo/svc", "repo/lib/db", "database/sql")
+	addFakePackage(graph, "repo/svc", "repo/mid")
+	addFakePackage(graph, "repo/mid", "repo/exp/a")
+	addFakePackage(graph, "repo/exp/a", "repo/exp/b")
+	addFakePackage(graph, "repo/exp/b")
+	addFakePackage(graph, "repo/lib/db", "database/sql")
+	addFakePackage(graph, "database/sql")
+
+	return graph
+}
+
+func TestPolicyViolations(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policy   *Policy
+		expected []*PolicyViolation
+	}{
+		{
+			name:   "forbid transitively",
+			policy: &Policy{Name: "no-exp", Packages: []string{"./cmd", "./svc/..."}, Forbid: []string{"./exp/..."}},
+			expected: []*PolicyViolation{
+				// The search stops at the first forbidden package, so repo/exp/b is not reported.
+				{Policy: "no-exp", Package: "repo/cmd", Import: "repo/exp/a", Chain: []string{"repo/cmd", "repo/svc", "repo/mid", "repo/exp/a"}},
+				{Policy: "no-exp", Package: "repo/svc", Import: "repo/exp/a", Chain: []string{"repo/svc", "repo/mid", "repo/exp/a"}},
+			},
+		},
+		{
+			name:   "forbid from all packages",
+			policy: &Policy{Name: "no-exp", Forbid: []string{"./exp/..."}},
+			expected: []*PolicyViolation{
+				// Forbidden packages may import each other.
+				{Policy: "no-exp", Package: "repo/cmd", Import: "repo/exp/a", Chain: []string{"repo/cmd", "repo/svc", "repo/mid", "repo/exp/a"}},
+				{Policy: "no-exp", Package: "repo/mid", Import: "repo/exp/a", Chain: []string{"repo/mid", "repo/exp/a"}},
+				{Policy: "no-exp", Package: "repo/svc", Import: "repo/exp/a", Chain: []string{"repo/svc", "repo/mid", "repo/exp/a"}},
+			},
+		},
+		{
+			name:   "forbid several",
+			policy: &Policy{Name: "no-sql", Packages: []string{"./cmd"}, Forbid: []string{"database/sql", "./exp/b"}},
+			expected: []*PolicyViolation{
+				{Policy: "no-sql", Package: "repo/cmd", Import: "database/sql", Chain: []string{"repo/cmd", "database/sql"}},
+				{Policy: "no-sql", Package: "repo/cmd", Import: "repo/exp/b", Chain: []string{"repo/cmd", "repo/svc", "repo/mid", "repo/exp/a", "repo/exp/b"}},
+			},
+		},
+		{
+			name:   "imports allowed",
+			policy: &Policy{Name: "sql-access", Imports: []string{"database/sql"}, Allow: []string{"./lib/db"}},
+			expected: []*PolicyViolation{
+				{Policy: "sql-access", Package: "repo/cmd", Import: "database/sql", Chain: []string{"repo/cmd", "database/sql"}},
+			},
+		},
+		{
+			name:     "imports only checks direct imports",
+			policy:   &Policy{Name: "exp-access", Imports: []string{"./exp/b"}, Allow: []string{"./exp/a"}},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("case=%s", tc.name), func(t *testing.T) {
+			d := &diff{config: &Config{Policies: []*Policy{tc.policy}}, packagePrefix: "repo"}
+			if actual := d.policyViolations(testPolicyGraph()); !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("Expected %s but got %s", violationString(tc.expected), violationString(actual))
+			}
+		})
+	}
+}
+
+func TestDeterminePolicyViolations(t *testing.T) {
+	oldGraph := testPolicyGraph()
+	newGraph := testPolicyGraph()
+	addFakePackage(newGraph, "repo/lib/db", "database/sql", "repo/exp/b")
+	addFakePackage(newGraph, "repo/cmd", "repo/svc", "repo/lib/db", "database/sql").ImportPos = map[string][]token.Position{
+		"repo/lib/db": {{Filename: "/src/repo/cmd/main.go", Line: 5}},
+	}
+
+	d := &diff{
+		git:           &lib.Git{RootDir: "/src/repo"},
+		packagePrefix: "repo",
+		config:        &Config{Policies: []*Policy{{Name: "no-exp", Packages: []string{"./cmd"}, Forbid: []string{"./exp/..."}}}},
+		graph:         newGraph,
+	}
+	if err := d.determinePolicyViolations(oldGraph, newGraph); err != nil {
+		t.Fatal(err)
+	}
+
+	// The violation through repo/svc was already in the old graph, so only the chain through repo/lib/db is new.
+	expected := []*PolicyViolation{
+		{
+			Policy:  "no-exp",
+			Package: "repo/cmd",
+			Import:  "repo/exp/b",
+			Chain:   []string{"repo/cmd", "repo/lib/db", "repo/exp/b"},
+			File:    "cmd/main.go",
+			Line:    5,
+		},
+	}
+	if !reflect.DeepEqual(expected, d.summary.Violations) {
+		t.Fatalf("Expected %s but got %s", violationString(expected), violationString(d.summary.Violations))
+	}
+}
+
+func TestGeneratorPolicyViolations(t *testing.T) {
+	// At HEAD, repo/lib runs the forbidden repo/exp/gen with a //go:generate directive.
+	oldGraph := testPolicyGraph()
+	graph := testPolicyGraph()
+	lib := addFakePackage(graph, "repo/lib/db", "database/sql")
+	lib.ImportVendoredPaths["repo/exp/gen"] = "repo/exp/gen"
+	lib.GenerateImports = []string{"repo/exp/gen"}
+	addFakePackage(graph, "repo/exp/gen")
+
+	d := &diff{
+		packagePrefix: "repo",
+		config:        &Config{Policies: []*Policy{{Name: "no-exp", Packages: []string{"./lib/..."}, Forbid: []string{"./exp/..."}}}},
+		graph:         graph,
+	}
+	d.summary.RootImportPath = "repo/cmd"
+	newGraph := productionGraph(graph.WithoutTests(), "repo/cmd")
+	if err := d.determinePolicyViolations(productionGraph(oldGraph.WithoutTests(), "repo/cmd"), newGraph); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []*PolicyViolation{
+		{Policy: "no-exp", Package: "repo/lib/db", Import: "repo/exp/gen", Chain: []string{"repo/lib/db", "repo/exp/gen"}},
+	}
+	if !reflect.DeepEqual(expected, d.summary.Violations) {
+		t.Fatalf("Expected %s but got %s", violationString(expected), violationString(d.summary.Violations))
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		policy *Policy
+		valid  bool
+	}{
+		{name: "forbid", policy: &Policy{Name: "a", Forbid: []string{"./x"}}, valid: true},
+		{name: "imports", policy: &Policy{Name: "a", Imports: []string{"./x"}}, valid: true},
+		{name: "imports with allow", policy: &Policy{Name: "a", Imports: []string{"./x"}, Allow: []string{"./y"}}, valid: true},
+		{name: "no name", policy: &Policy{Forbid: []string{"./x"}}},
+		{name: "neither", policy: &Policy{Name: "a", Packages: []string{"./y"}}},
+		{name: "both", policy: &Policy{Name: "a", Forbid: []string{"./x"}, Imports: []string{"./x"}}},
+		{name: "forbid with allow", policy: &Policy{Name: "a", Forbid: []string{"./x"}, Allow: []string{"./y"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("case=%s", tc.name), func(t *testing.T) {
+			if err := tc.policy.validate(); (err == nil) != tc.valid {
+				t.Fatalf("Expected valid=%v but got error %v", tc.valid, err)
+			}
+		})
+	}
+}
+
+func TestProductionGraphFrom(t *testing.T) {
+	headGraph := &importer.PackageGraph{Packages: make(map[string]*importer.Package)}
+	addFakePackage(headGraph, "repo/cmd", "repo/new", "vendored/dep")
+	headGraph.Packages["repo/cmd"].ImportVendoredPaths["vendored/dep"] = "repo/vendor/vendored/dep"
+	headGraph.Packages["repo/cmd"].TestImports = []string{"testing"}
+	addFakePackage(headGraph, "repo/new")
+	addFakePackage(headGraph, "repo/vendor/vendored/dep", "fmt")
+	addFakePackage(headGraph, "fmt", "io")
+	addFakePackage(headGraph, "io")
+	addFakePackage(headGraph, "testing")
+
+	// At the old commit, repo/cmd imported repo/old, which is no longer in the graph, instead of repo/new.
+	sources := map[string]map[string][]byte{
+		"repo/cmd": {
+			"cmd/a.go": []byte("package main\nimport (\n\t\"repo/old\"\n\t\"vendored/dep\"\n)\n"),
+			"cmd/b.go": []byte("package main\nimport \"os\"\n"),
+		},
+		"repo/old":                 {"old/old.go": []byte("package old\nimport \"repo/older\"\n")},
+		"repo/older":               {"older/older.go": []byte("package older\n")},
+		"repo/vendor/vendored/dep": {"vendor/vendored/dep/dep.go": []byte("package dep\nimport \"fmt\"\n")},
+	}
+
+	d := &diff{packagePrefix: "repo", graph: headGraph}
+	d.summary.RootImportPath = "repo/cmd"
+	graph, err := d.productionGraphFrom(func(pkg string) (map[string][]byte, error) {
+		return sources[pkg], nil
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{
+		"repo/cmd":                 {"os", "repo/old", "repo/vendor/vendored/dep"},
+		"repo/old":                 {"repo/older"},
+		"repo/older":               {},
+		"repo/vendor/vendored/dep": {"fmt"},
+		"fmt":                      {"io"},
+		"io":                       {},
+		"os":                       {},
+	}
+	if actual := graph.ToMap(); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %v but got %v", expected, actual)
+	}
+
+	// Generators are resolved like imports, and are part of the graph.
+	sources["repo/gen"] = map[string][]byte{"gen/gen.go": []byte("package main\nimport \"vendored/dep\"\n")}
+	graph, err = d.productionGraphFrom(func(pkg string) (map[string][]byte, error) {
+		return sources[pkg], nil
+	}, func(pkg string) ([]string, error) {
+		if pkg == "repo/cmd" {
+			return []string{"repo/gen", "vendored/dep", "repo/gen"}, nil
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := []string{"repo/gen", "repo/vendor/vendored/dep"}, graph.Packages["repo/cmd"].GenerateImports; !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected generate imports %v but got %v", expected, actual)
+	}
+	// The generator is not in the graph at HEAD, so its imports are not resolved to vendored packages.
+	if expected, actual := []string{"vendored/dep"}, productionImports(graph.Packages["repo/gen"]); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected generator imports %v but got %v", expected, actual)
+	}
+}
+
+func TestProductionGraph(t *testing.T) {
+	graph := &importer.PackageGraph{Packages: make(map[string]*importer.Package)}
+	addFakePackage(graph, "repo/cmd", "repo/lib").TestImports = []string{"repo/testutil"}
+	addFakePackage(graph, "repo/lib", "C")
+	addFakePackage(graph, "repo/testutil", "repo/lib")
+	addFakePackage(graph, "repo/unreachable", "repo/cmd")
+
+	expected := map[string][]string{
+		"repo/cmd": {"repo/lib"},
+		"repo/lib": {"C"},
+	}
+	if actual := productionGraph(graph.WithoutTests(), "repo/cmd").ToMap(); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func violationString(violations []*PolicyViolation) string {
+	var s string
+	for _, violation := range violations {
+		s += fmt.Sprintf("\n%+v", *violation)
+	}
+	return s
+}
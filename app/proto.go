@@ -0,0 +1,135 @@
+package app
+
+import (
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alecholmes/tdiff/importer"
+	"github.com/alecholmes/tdiff/lib"
+	"github.com/alecholmes/tdiff/source"
+)
+
+// protoDependents returns a fileDependents for .proto files that generated Go files in
+// reachable packages were generated from, including the .proto files they import.
+func protoDependents(graph *importer.PackageGraph, git *lib.Git) (fileDependents, error) {
+	trackedFiles, err := git.TrackedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	return protoFileDependents(graph, git.RootDir, trackedFiles)
+}
+
+// protoFileDependents returns a fileDependents for .proto files among the tracked files of the Git
+// repository at the given root directory.
+//
+// Generated files name their source relative to a protoc include root, which isn't known. The source is
+// resolved relative to the directory of the generated file and each of its parent directories, nearest first,
+// and imports of a .proto file are resolved relative to the include root its own path was resolved with first.
+func protoFileDependents(graph *importer.PackageGraph, gitRoot string, trackedFiles []string) (fileDependents, error) {
+	tracked := make(lib.StringSet)
+	tracked.Add(trackedFiles...)
+
+	type protoFile struct {
+		file string // Relative to the root of the Git repository
+		root string // Include root the file was resolved relative to
+	}
+
+	protoPackages := make(map[string]lib.StringSet) // Reachable packages by proto file
+	for importPath, pkg := range graph.Packages {
+		if pkg.Goroot {
+			continue
+		}
+
+		dir, err := filepath.Rel(gitRoot, pkg.Dir)
+		if err != nil || strings.HasPrefix(dir, "..") {
+			dir = "."
+		}
+		packageRoots := parentDirs(filepath.ToSlash(dir))
+
+		var queue []protoFile
+		for _, file := range pkg.GoFiles {
+			body, err := ioutil.ReadFile(filepath.Join(pkg.Dir, file))
+			if err != nil {
+				return nil, err
+			}
+			if protoSource := source.GeneratedSource(body); len(protoSource) > 0 {
+				if file, root := resolveTrackedFile(protoSource, packageRoots, tracked); len(file) > 0 {
+					queue = append(queue, protoFile{file: file, root: root})
+				}
+			}
+		}
+
+		// Follow imports of the proto sources.
+		visited := make(lib.StringSet)
+		for ; len(queue) > 0; queue = queue[1:] {
+			current := queue[0]
+			if visited.Contains(current.file) {
+				continue
+			}
+			visited.Add(current.file)
+
+			if _, ok := protoPackages[current.file]; !ok {
+				protoPackages[current.file] = make(lib.StringSet)
+			}
+			protoPackages[current.file].Add(importPath)
+
+			body, err := ioutil.ReadFile(filepath.Join(gitRoot, current.file))
+			if err != nil {
+				// Tracked files may have been deleted from the working directory.
+				continue
+			}
+			importRoots := append([]string{current.root}, parentDirs(path.Dir(current.file))...)
+			for _, protoImport := range source.ProtoImports(body) {
+				if file, root := resolveTrackedFile(protoImport, importRoots, tracked); len(file) > 0 {
+					queue = append(queue, protoFile{file: file, root: root})
+				}
+			}
+		}
+	}
+
+	return func(file string) []string {
+		packages := protoPackages[file].Slice()
+		sort.Strings(packages)
+		return packages
+	}, nil
+}
+
+// resolveTrackedFile returns the tracked file that a path relative to an include root names, and the root.
+// Roots are directories relative to the root of the Git repository, where "." is the root itself, and are tried
+// in order. If the path isn't found under any root, the tracked file ending with it is returned, provided there is
+// exactly one. An empty file is returned if the path can't be resolved.
+func resolveTrackedFile(name string, roots []string, trackedFiles lib.StringSet) (string, string) {
+	for _, root := range roots {
+		if file := path.Join(root, name); trackedFiles.Contains(file) {
+			return file, root
+		}
+	}
+
+	var matches []string
+	for file := range trackedFiles {
+		if strings.HasSuffix(file, "/"+name) {
+			matches = append(matches, file)
+		}
+	}
+	if len(matches) != 1 {
+		return "", ""
+	}
+
+	return matches[0], strings.TrimSuffix(matches[0], "/"+name)
+}
+
+// parentDirs returns a directory relative to the root of the Git repository followed by each of its parents,
+// ending with the root, ".".
+func parentDirs(dir string) []string {
+	dirs := []string{dir}
+	for dir != "." && dir != "/" {
+		dir = path.Dir(dir)
+		dirs = append(dirs, dir)
+	}
+
+	return dirs
+}
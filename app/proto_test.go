@@ -0,0 +1,117 @@
+package app
+
+import (
+	"fmt"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/alecholmes/tdiff/importer"
+	"github.com/alecholmes/tdiff/lib"
+)
+
+func TestResolveTrackedFile(t *testing.T) {
+	tracked := make(lib.StringSet)
+	tracked.Add(
+		"api.proto",
+		"proto/api.proto",
+		"proto/common/types.proto",
+		"services/a/api.proto",
+		"services/b/api.proto",
+		"third_party/google/protobuf/empty.proto",
+	)
+
+	testCases := []struct {
+		name         string
+		roots        []string
+		expectedFile string
+		expectedRoot string
+	}{
+		{name: "api.proto", roots: []string{"services/a", "services", "."}, expectedFile: "services/a/api.proto", expectedRoot: "services/a"},
+		{name: "api.proto", roots: []string{"services/c", "services", "."}, expectedFile: "api.proto", expectedRoot: "."},
+		{name: "api.proto", roots: []string{"proto", "."}, expectedFile: "proto/api.proto", expectedRoot: "proto"},
+		{name: "common/types.proto", roots: []string{"proto", "."}, expectedFile: "proto/common/types.proto", expectedRoot: "proto"},
+		// Unresolved paths fall back to the only tracked file ending with the path.
+		{name: "google/protobuf/empty.proto", roots: []string{"."}, expectedFile: "third_party/google/protobuf/empty.proto", expectedRoot: "third_party"},
+		{name: "types.proto", roots: []string{"gen"}, expectedFile: "proto/common/types.proto", expectedRoot: "proto/common"},
+		{name: "a/api.proto", roots: []string{"gen"}, expectedFile: "services/a/api.proto", expectedRoot: "services"},
+		// Missing paths are not resolved.
+		{name: "missing.proto", roots: []string{"."}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("name=%s,roots=%v", tc.name, tc.roots), func(t *testing.T) {
+			file, root := resolveTrackedFile(tc.name, tc.roots, tracked)
+			if file != tc.expectedFile || root != tc.expectedRoot {
+				t.Fatalf("Expected %q in %q but got %q in %q", tc.expectedFile, tc.expectedRoot, file, root)
+			}
+		})
+	}
+
+	ambiguous := make(lib.StringSet)
+	ambiguous.Add("services/a/api.proto", "services/b/api.proto")
+	if file, _ := resolveTrackedFile("api.proto", []string{"gen", "."}, ambiguous); len(file) > 0 {
+		t.Fatalf("Expected ambiguous path not to be resolved but got %s", file)
+	}
+}
+
+func TestProtoDependents(t *testing.T) {
+	root, err := ioutil.TempDir("", "tdiff-proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	files := map[string]string{
+		"services/a/api.proto":                    "syntax = \"proto3\";\nimport \"common/types.proto\";\n",
+		"services/b/api.proto":                    "syntax = \"proto3\";\n",
+		"services/common/types.proto":             "syntax = \"proto3\";\nimport \"google/protobuf/empty.proto\";\n",
+		"third_party/google/protobuf/empty.proto": "syntax = \"proto3\";\n",
+		"services/a/api.pb.go":                    "// Code generated by protoc-gen-go. DO NOT EDIT.\n// source: api.proto\n\npackage a\n",
+		"services/b/api.pb.go":                    "// Code generated by protoc-gen-go. DO NOT EDIT.\n// source: api.proto\n\npackage b\n",
+		"services/b/handwritten.go":               "// source: ../a/api.proto\n\npackage b\n",
+	}
+	var trackedFiles []string
+	for file, body := range files {
+		path := filepath.Join(root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+		trackedFiles = append(trackedFiles, file)
+	}
+
+	graph := &importer.PackageGraph{Packages: map[string]*importer.Package{
+		"repo/services/a": {Package: &build.Package{ImportPath: "repo/services/a", Dir: filepath.Join(root, "services", "a"), GoFiles: []string{"api.pb.go"}}},
+		"repo/services/b": {Package: &build.Package{ImportPath: "repo/services/b", Dir: filepath.Join(root, "services", "b"), GoFiles: []string{"api.pb.go", "handwritten.go"}}},
+	}}
+
+	dependents, err := protoFileDependents(graph, root, trackedFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		file     string
+		expected []string
+	}{
+		{file: "services/a/api.proto", expected: []string{"repo/services/a"}},
+		{file: "services/b/api.proto", expected: []string{"repo/services/b"}},
+		{file: "services/common/types.proto", expected: []string{"repo/services/a"}},
+		{file: "third_party/google/protobuf/empty.proto", expected: []string{"repo/services/a"}},
+		{file: "services/a/api.pb.go", expected: []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("file=%s", tc.file), func(t *testing.T) {
+			if actual := dependents(tc.file); !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("Expected %v but got %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/alecholmes/tdiff/lib"
+	"github.com/alecholmes/tdiff/source"
+)
+
+// Risk signals, by the names used for their weights.
+const (
+	RiskPackages   = "packages"   // Relevant changed packages
+	RiskChurn      = "churn"      // Lines added and deleted in relevant files
+	RiskFanIn      = "fanIn"      // Total fan-in of relevant changed packages in the root's graph
+	RiskProdFiles  = "prodFiles"  // Relevant changed files other than tests
+	RiskTestFiles  = "testFiles"  // Relevant changed _test.go files
+	RiskNewImports = "newImports" // Import paths added to relevant Go files
+)
+
+// DefaultRiskWeights are the weights of risk signals not given a weight in the config.
+var DefaultRiskWeights = map[string]float64{
+	RiskPackages:   1,
+	RiskChurn:      0.01,
+	RiskFanIn:      0.1,
+	RiskProdFiles:  0.5,
+	RiskTestFiles:  0.1,
+	RiskNewImports: 2,
+}
+
+// RiskConfig configures how the risk of changes is scored.
+// The score is the sum of each signal multiplied by its weight.
+type RiskConfig struct {
+	Weights   map[string]float64 `json:"weights"`   // Weights by signal name; see DefaultRiskWeights
+	Threshold float64            `json:"threshold"` // If positive, scores above this exceed the threshold
+}
+
+// Risk is the risk score of a range of changes or a single commit.
+type Risk struct {
+	Score     float64        `json:"score"`
+	Signals   map[string]int `json:"signals"`             // Value of each signal, by name
+	Threshold float64        `json:"threshold,omitempty"` // Threshold of the score, if any
+	Exceeded  bool           `json:"exceeded,omitempty"`  // Whether the score is above the threshold
+}
+
+// determineRisk scores the risk of the whole range of changes, and prepares scoring the risk of each
+// relevant commit as it is determined (see scoreCommitRisk). The threshold overrides the config's threshold,
+// if positive.
+func (d *diff) determineRisk(threshold float64) error {
+	weights, err := riskWeights(d.config.Risk)
+	if err != nil {
+		return err
+	}
+	if threshold <= 0 && d.config.Risk != nil {
+		threshold = d.config.Risk.Threshold
+	}
+
+	fanIns := make(map[string]int)
+	for _, pkg := range d.summary.Packages {
+		if pkg.Impact != nil {
+			fanIns[pkg.ImportPath] = pkg.Impact.FanIn
+			continue
+		}
+		dependents, err := d.graph.Dependents(pkg.ImportPath)
+		if err != nil {
+			return err
+		}
+		fanIns[pkg.ImportPath] = len(dependents)
+	}
+
+	churn, err := d.git.DiffChurn(d.summary.SHA, "HEAD", d.summary.Files...)
+	if err != nil {
+		return err
+	}
+	newImports, err := d.newImports(d.summary.SHA, "HEAD", d.summary.Files)
+	if err != nil {
+		return err
+	}
+	signals := riskSignals(d.summary.Packages, d.summary.Files, fanIns, churn, newImports)
+	d.summary.Risk = scoreRisk(signals, weights, threshold)
+	d.riskWeights = weights
+	d.riskFanIns = fanIns
+
+	return nil
+}
+
+// scoreCommitRisk scores the risk of a relevant commit, if risk is scored.
+func (d *diff) scoreCommitRisk(commit *Commit) error {
+	if d.riskWeights == nil {
+		return nil
+	}
+
+	churn, err := d.git.CommitChurn(commit.SHA, commit.Files...)
+	if err != nil {
+		return err
+	}
+	newImports, err := d.newImports(commit.SHA+"^", commit.SHA, commit.Files)
+	if err != nil {
+		return err
+	}
+	commit.Risk = scoreRisk(riskSignals(commit.RelevantPackages, commit.Files, d.riskFanIns, churn, newImports), d.riskWeights, 0)
+
+	return nil
+}
+
+// riskWeights returns the weight of each risk signal: the config's weight if given, and otherwise the default.
+// An error is returned if the config weighs an unknown signal.
+func riskWeights(config *RiskConfig) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	for signal, weight := range DefaultRiskWeights {
+		weights[signal] = weight
+	}
+	if config == nil {
+		return weights, nil
+	}
+
+	for signal, weight := range config.Weights {
+		if _, ok := DefaultRiskWeights[signal]; !ok {
+			return nil, fmt.Errorf("Unknown risk signal `%s`", signal)
+		}
+		weights[signal] = weight
+	}
+
+	return weights, nil
+}
+
+// riskSignals returns the value of every risk signal for changes to packages and files, given the fan-in of
+// the packages, the churn of the files and the number of new imports.
+func riskSignals(packages []*Package, files []string, fanIns, churn map[string]int, newImports int) map[string]int {
+	signals := make(map[string]int)
+	for signal := range DefaultRiskWeights {
+		signals[signal] = 0
+	}
+	signals[RiskPackages] = len(packages)
+	signals[RiskNewImports] = newImports
+	for _, pkg := range packages {
+		signals[RiskFanIn] += fanIns[pkg.ImportPath]
+	}
+	for _, file := range files {
+		signals[RiskChurn] += churn[file]
+		if strings.HasSuffix(file, "_test.go") {
+			signals[RiskTestFiles]++
+		} else {
+			signals[RiskProdFiles]++
+		}
+	}
+
+	return signals
+}
+
+// scoreRisk scores signals as the sum of each signal multiplied by its weight, rounded to two decimals.
+// If the threshold is positive, the risk records whether the score exceeds it.
+func scoreRisk(signals map[string]int, weights map[string]float64, threshold float64) *Risk {
+	risk := &Risk{Signals: signals}
+	for signal, value := range signals {
+		risk.Score += weights[signal] * float64(value)
+	}
+	risk.Score = math.Round(risk.Score*100) / 100
+
+	if threshold > 0 {
+		risk.Threshold = threshold
+		risk.Exceeded = risk.Score > threshold
+	}
+
+	return risk
+}
+
+// newImports returns the number of import paths that the given Go files import at toSHA but did not at fromSHA.
+func (d *diff) newImports(fromSHA, toSHA string, files []string) (int, error) {
+	added, err := d.addedImports(fromSHA, toSHA, files)
+	if err != nil {
+		return 0, err
+	}
+
+	importPaths := make(lib.StringSet)
+	for _, addedImport := range added {
+		importPaths.Add(addedImport.Path)
+	}
+
+	return len(importPaths), nil
+}
+
+// addedImport is an import by a file of an import path that was not previously imported.
+type addedImport struct {
+	file string
+	source.Import
+}
+
+// addedImports returns the imports by the given Go files at toSHA of import paths that none of
+// the files imported at fromSHA, ordered by file and line.
+func (d *diff) addedImports(fromSHA, toSHA string, files []string) ([]addedImport, error) {
+	var goFiles []string
+	for _, file := range files {
+		if strings.HasSuffix(file, ".go") {
+			goFiles = append(goFiles, file)
+		}
+	}
+	sort.Strings(goFiles)
+
+	imports := func(sha, file string) ([]source.Import, error) {
+		body, err := d.git.FileAt(sha, file)
+		if err != nil {
+			return nil, err
+		}
+		fileImports, err := source.Imports(body)
+		if err != nil {
+			// Unparseable files don't contribute imports
+			return nil, nil
+		}
+		return fileImports, nil
+	}
+
+	oldImports := make(lib.StringSet)
+	for _, file := range goFiles {
+		fileImports, err := imports(fromSHA, file)
+		if err != nil {
+			return nil, err
+		}
+		for _, fileImport := range fileImports {
+			oldImports.Add(fileImport.Path)
+		}
+	}
+
+	var added []addedImport
+	for _, file := range goFiles {
+		fileImports, err := imports(toSHA, file)
+		if err != nil {
+			return nil, err
+		}
+		for _, fileImport := range fileImports {
+			if !oldImports.Contains(fileImport.Path) {
+				added = append(added, addedImport{file: file, Import: fileImport})
+			}
+		}
+	}
+
+	return added, nil
+}
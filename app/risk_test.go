@@ -0,0 +1,113 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestRiskWeights(t *testing.T) {
+	weights, err := riskWeights(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(DefaultRiskWeights, weights) {
+		t.Fatalf("Expected default weights %v but got %v", DefaultRiskWeights, weights)
+	}
+
+	weights, err = riskWeights(&RiskConfig{Weights: map[string]float64{RiskChurn: 0.5, RiskTestFiles: 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if weights[RiskChurn] != 0.5 || weights[RiskTestFiles] != 0 || weights[RiskPackages] != DefaultRiskWeights[RiskPackages] {
+		t.Fatalf("Expected config weights to override defaults but got %v", weights)
+	}
+	if DefaultRiskWeights[RiskChurn] == 0.5 {
+		t.Fatal("Expected default weights not to be modified")
+	}
+
+	if _, err := riskWeights(&RiskConfig{Weights: map[string]float64{"lines": 1}}); err == nil {
+		t.Fatal("Expected an error for an unknown signal")
+	}
+}
+
+func TestRiskSignals(t *testing.T) {
+	packages := []*Package{{ImportPath: "repo/a"}, {ImportPath: "repo/b"}}
+	files := []string{"a/a.go", "a/a_test.go", "b/b.go", "b/schema.sql"}
+	fanIns := map[string]int{"repo/a": 3, "repo/b": 1, "repo/c": 10}
+	churn := map[string]int{"a/a.go": 10, "a/a_test.go": 5, "b/b.go": 2, "c/c.go": 100}
+
+	expected := map[string]int{
+		RiskPackages:   2,
+		RiskChurn:      17,
+		RiskFanIn:      4,
+		RiskProdFiles:  3,
+		RiskTestFiles:  1,
+		RiskNewImports: 2,
+	}
+	if actual := riskSignals(packages, files, fanIns, churn, 2); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %v but got %v", expected, actual)
+	}
+
+	// Every signal is present, even without changes.
+	expected = map[string]int{RiskPackages: 0, RiskChurn: 0, RiskFanIn: 0, RiskProdFiles: 0, RiskTestFiles: 0, RiskNewImports: 0}
+	if actual := riskSignals(nil, nil, fanIns, churn, 0); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestScoreRisk(t *testing.T) {
+	signals := map[string]int{
+		RiskPackages:   2,
+		RiskChurn:      17,
+		RiskFanIn:      4,
+		RiskProdFiles:  3,
+		RiskTestFiles:  1,
+		RiskNewImports: 2,
+	}
+
+	testCases := []struct {
+		name      string
+		weights   map[string]float64
+		threshold float64
+		expected  *Risk
+	}{
+		{
+			// 2*1 + 17*0.01 + 4*0.1 + 3*0.5 + 1*0.1 + 2*2
+			name:     "default weights",
+			weights:  DefaultRiskWeights,
+			expected: &Risk{Score: 8.17, Signals: signals},
+		},
+		{
+			name:      "below threshold",
+			weights:   DefaultRiskWeights,
+			threshold: 10,
+			expected:  &Risk{Score: 8.17, Signals: signals, Threshold: 10},
+		},
+		{
+			name:      "at threshold",
+			weights:   DefaultRiskWeights,
+			threshold: 8.17,
+			expected:  &Risk{Score: 8.17, Signals: signals, Threshold: 8.17},
+		},
+		{
+			name:      "above threshold",
+			weights:   DefaultRiskWeights,
+			threshold: 8,
+			expected:  &Risk{Score: 8.17, Signals: signals, Threshold: 8, Exceeded: true},
+		},
+		{
+			name:     "rounded",
+			weights:  map[string]float64{RiskChurn: 1.0 / 3},
+			expected: &Risk{Score: 5.67, Signals: signals},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("case=%s", tc.name), func(t *testing.T) {
+			if actual := scoreRisk(signals, tc.weights, tc.threshold); !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("Expected %+v but got %+v", tc.expected, actual)
+			}
+		})
+	}
+}
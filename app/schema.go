@@ -0,0 +1,18 @@
+package app
+
+import "encoding/json"
+
+// SchemaVersion is the version of the JSON encoding of Summary, described by the JSON Schema in
+// schema/summary.schema.json. Within a version fields are only added; removing, renaming or changing
+// the type of a field increments the version.
+const SchemaVersion = 1
+
+// MarshalJSON encodes the package with its import path as importPath, and also as name, which
+// is kept for compatibility with consumers of earlier output.
+func (p *Package) MarshalJSON() ([]byte, error) {
+	type pkg Package
+	return json.Marshal(struct {
+		ImportPath string `json:"importPath"`
+		*pkg
+	}{p.ImportPath, (*pkg)(p)})
+}
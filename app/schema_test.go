@@ -0,0 +1,442 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/alecholmes/tdiff/lib"
+	"github.com/alecholmes/tdiff/source"
+)
+
+var updateGolden = flag.Bool("update", false, "If set, golden files are rewritten with the current output")
+
+// testSummaries returns summaries for golden tests by name: a minimal summary, as returned by Diff
+// when nothing changed, and a summary with every field set.
+func testSummaries() map[string]*Summary {
+	pkg := &Package{
+		ImportPath:     "example.com/repo/lib",
+		PathFromRoot:   []string{"example.com/repo/cmd", "example.com/repo/lib"},
+		Paths:          [][]string{{"example.com/repo/cmd", "example.com/repo/lib"}},
+		Importers:      []string{"example.com/repo/cmd"},
+		Files:          []string{"lib/lib.go"},
+		ChangedSymbols: []string{"Helper"},
+		APIChanges: []*source.APIChange{
+			{Name: "Helper", Kind: source.APIFunc, Change: source.APIChanged, Breaking: true, OldSignature: "func()", NewSignature: "func(int)"},
+		},
+		Classification: source.ChangeCode,
+		Impact:         &Impact{Depth: 1, FanIn: 1, Reach: 0.5},
+		Owners:         []string{"@org/lib"},
+	}
+	risk := &Risk{Score: 3.5, Signals: map[string]int{RiskPackages: 1, RiskChurn: 50}, Threshold: 3, Exceeded: true}
+
+	return map[string]*Summary{
+		"minimal": {
+			SchemaVersion:  SchemaVersion,
+			RootImportPath: "example.com/repo/cmd",
+			SHA:            "0000000000000000000000000000000000000000",
+			Packages:       []*Package{},
+			Commits:        []*Commit{},
+			Bump:           BumpNone,
+			Files:          []string{},
+		},
+		"full": {
+			SchemaVersion:  SchemaVersion,
+			RootImportPath: "example.com/repo/cmd",
+			SHA:            "0000000000000000000000000000000000000000",
+			Packages:       []*Package{pkg},
+			Commits: []*Commit{
+				{
+					SHA:              "1111111111111111111111111111111111111111",
+					Description:      "feat(lib)!: take a count PROJ-1",
+					Conventional:     &lib.ConventionalCommit{Type: "feat", Scope: "lib", Breaking: true, Description: "take a count PROJ-1"},
+					RelevantPackages: []*Package{pkg},
+					Classification:   source.ChangeCode,
+					Files:            []string{"lib/lib.go"},
+					Patch:            "diff --git a/lib/lib.go b/lib/lib.go\n",
+					Risk:             risk,
+					Issues:           []string{"PROJ-1"},
+				},
+			},
+			OtherCommits: []*Commit{
+				{SHA: "2222222222222222222222222222222222222222", Description: "docs: readme", RelevantPackages: []*Package{}, Files: []string{}},
+			},
+			Bump: BumpMajor,
+			Issues: []*Issue{
+				{ID: "PROJ-1", URL: "https://issues.example.com/PROJ-1", Commits: []string{"1111111111111111111111111111111111111111"}, Packages: []string{"example.com/repo/lib"}},
+			},
+			Files:               []string{"lib/lib.go"},
+			FileClassifications: map[string]string{"lib/lib.go": source.ChangeCode},
+			FileOwners:          map[string][]string{"lib/lib.go": {"@org/lib"}},
+			Owners:              []*Owner{{Name: "@org/lib", Packages: []string{"example.com/repo/lib"}, Files: []string{"lib/lib.go"}}},
+			Contributors:        []*Contributor{{Name: "Alice", Email: "alice@example.com", Team: "lib", Lines: 10, Files: 1}},
+			Teams:               []*Team{{Name: "lib", Lines: 10, Contributors: []string{"alice@example.com"}}},
+			Risk:                risk,
+			Violations: []*PolicyViolation{
+				{Policy: "no-experimental", Package: "example.com/repo/lib", Import: "example.com/repo/experimental", Chain: []string{"example.com/repo/lib", "example.com/repo/experimental"}, File: "lib/lib.go", Line: 3},
+			},
+			LayerViolations: []*LayerViolation{
+				{Package: "example.com/repo/lib", PackageLayer: "libraries", Import: "example.com/repo/cmd", ImportLayer: "commands", File: "lib/lib.go", Line: 4},
+			},
+			Cycles: []*DirectoryCycle{
+				{
+					Directories: []string{"a", "b"},
+					Chain:       []string{"a", "b", "a"},
+					Imports: []*DirectoryImport{
+						{From: "example.com/repo/a", To: "example.com/repo/b", File: "a/a.go", Line: 3},
+						{From: "example.com/repo/b", To: "example.com/repo/a/c", File: "b/b.go", Line: 3},
+					},
+				},
+			},
+			Findings: []*Finding{
+				{Rule: RuleNewImport, Level: LevelWarning, Message: "New import of fmt", File: "lib/lib.go", Line: 3},
+			},
+		},
+	}
+}
+
+func TestSummaryJSONGolden(t *testing.T) {
+	for name, summary := range testSummaries() {
+		t.Run(fmt.Sprintf("summary=%s", name), func(t *testing.T) {
+			actual, err := json.MarshalIndent(summary, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, fmt.Sprintf("summary_%s.golden.json", name), append(actual, '\n'))
+		})
+	}
+}
+
+// loadSummarySchema reads the JSON Schema shipped with tdiff.
+func loadSummarySchema(t *testing.T) map[string]interface{} {
+	body, err := ioutil.ReadFile(filepath.Join("..", "schema", "summary.schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(body, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	return schema
+}
+
+// checkGolden compares output with a golden file in testdata, first rewriting the golden file if -update is set.
+// JSON golden files may only be rewritten by adding fields, so that output stays backward compatible within a
+// schema version: every key path of the golden file must still be present in the output, with the same JSON type.
+func checkGolden(t *testing.T, name string, actual []byte) {
+	golden := filepath.Join("testdata", name)
+	if *updateGolden {
+		if existing, err := ioutil.ReadFile(golden); err == nil && isJSONGolden(name) {
+			if errs := compareJSONKeyPaths(existing, actual); len(errs) > 0 {
+				t.Fatalf("Output is not backward compatible with %s; increment SchemaVersion and remove the golden file to rewrite it:\n%s",
+					golden, strings.Join(errs, "\n"))
+			}
+		}
+		if err := ioutil.WriteFile(golden, actual, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Fatalf("Output does not match %s; run go test with -update if the change is backward compatible.\nExpected:\n%s\nActual:\n%s", golden, expected, actual)
+	}
+}
+
+// isJSONGolden returns true if a golden file holds JSON or newline-delimited JSON of the summary schema.
+func isJSONGolden(name string) bool {
+	return strings.HasPrefix(name, "summary_") || strings.HasPrefix(name, "events_")
+}
+
+// compareJSONKeyPaths returns an error for each key path of the old JSON or newline-delimited JSON that is missing
+// from the new JSON, or has a different type. Lines of newline-delimited JSON are compared as items of an array.
+func compareJSONKeyPaths(oldBody, newBody []byte) []string {
+	keyPaths := func(body []byte) (map[string]lib.StringSet, error) {
+		paths := make(map[string]lib.StringSet)
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		for decoder.More() {
+			var value interface{}
+			if err := decoder.Decode(&value); err != nil {
+				return nil, err
+			}
+			addJSONKeyPaths(paths, "$", value)
+		}
+		return paths, nil
+	}
+
+	oldPaths, err := keyPaths(oldBody)
+	if err != nil {
+		return []string{fmt.Sprintf("Unable to parse golden file: %v", err)}
+	}
+	newPaths, err := keyPaths(newBody)
+	if err != nil {
+		return []string{fmt.Sprintf("Unable to parse output: %v", err)}
+	}
+
+	var errs []string
+	for path, types := range oldPaths {
+		for jsonType := range types {
+			if !newPaths[path].Contains(jsonType) {
+				errs = append(errs, fmt.Sprintf("%s: %s is missing", path, jsonType))
+			}
+		}
+	}
+	sort.Strings(errs)
+
+	return errs
+}
+
+// addJSONKeyPaths adds the key paths of a decoded JSON value, and their JSON types, to paths.
+// Items of arrays share the path of the array with "[]" appended.
+func addJSONKeyPaths(paths map[string]lib.StringSet, at string, value interface{}) {
+	if _, ok := paths[at]; !ok {
+		paths[at] = make(lib.StringSet)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		paths[at].Add("object")
+		for key, item := range v {
+			addJSONKeyPaths(paths, at+"."+key, item)
+		}
+	case []interface{}:
+		paths[at].Add("array")
+		for _, item := range v {
+			addJSONKeyPaths(paths, at+"[]", item)
+		}
+	case string:
+		paths[at].Add("string")
+	case float64:
+		paths[at].Add("number")
+	case bool:
+		paths[at].Add("boolean")
+	default:
+		paths[at].Add("null")
+	}
+}
+
+func TestCompareJSONKeyPaths(t *testing.T) {
+	testCases := []struct {
+		name     string
+		old      string
+		new      string
+		expected []string
+	}{
+		{name: "unchanged", old: `{"a": 1, "b": [{"c": "x"}]}`, new: `{"a": 1, "b": [{"c": "x"}]}`},
+		{name: "values changed", old: `{"a": 1, "b": [{"c": "x"}]}`, new: `{"a": 2, "b": [{"c": "y"}, {"c": "z"}]}`},
+		{name: "field added", old: `{"a": 1}`, new: `{"a": 1, "b": true}`},
+		{name: "field removed", old: `{"a": 1, "b": [{"c": "x"}]}`, new: `{"a": 1, "b": [{}]}`, expected: []string{"$.b[].c: string is missing"}},
+		{name: "type changed", old: `{"a": 1, "b": []}`, new: `{"a": "1", "b": null}`, expected: []string{"$.a: number is missing", "$.b: array is missing"}},
+		{name: "lines", old: "{\"type\": \"start\"}\n{\"type\": \"end\", \"bump\": \"none\"}\n", new: "{\"type\": \"start\"}\n", expected: []string{"$.bump: string is missing"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("case=%s", tc.name), func(t *testing.T) {
+			if actual := compareJSONKeyPaths([]byte(tc.old), []byte(tc.new)); !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("Expected %v but got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSummaryJSONSchema(t *testing.T) {
+	schema := loadSummarySchema(t)
+	if version := schema["properties"].(map[string]interface{})["schemaVersion"].(map[string]interface{})["const"]; version != float64(SchemaVersion) {
+		t.Fatalf("Expected schema version %d but got %v", SchemaVersion, version)
+	}
+
+	for name, summary := range testSummaries() {
+		t.Run(fmt.Sprintf("summary=%s", name), func(t *testing.T) {
+			body, err := json.Marshal(summary)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var value interface{}
+			if err := json.Unmarshal(body, &value); err != nil {
+				t.Fatal(err)
+			}
+
+			if errs := validateSchema(schema, schema, value, "$"); len(errs) > 0 {
+				t.Fatalf("JSON of summary does not match the schema:\n%s", strings.Join(errs, "\n"))
+			}
+		})
+	}
+}
+
+// validateSchema validates a decoded JSON value against the subset of JSON Schema used by the summary schema.
+// Unlike JSON Schema, properties of objects with declared properties must be declared, so that every field
+// is documented.
+func validateSchema(root, schema map[string]interface{}, value interface{}, at string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		definition := root
+		for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			definition = definition[part].(map[string]interface{})
+		}
+		return validateSchema(root, definition, value, at)
+	}
+
+	if expected, ok := schema["const"]; ok && !reflect.DeepEqual(expected, value) {
+		return []string{fmt.Sprintf("%s: expected %v but got %v", at, expected, value)}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, expected := range enum {
+			found = found || reflect.DeepEqual(expected, value)
+		}
+		if !found {
+			return []string{fmt.Sprintf("%s: %v is not one of %v", at, value, enum)}
+		}
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		matches := 0
+		for _, option := range oneOf {
+			if len(validateSchema(root, option.(map[string]interface{}), value, at)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return []string{fmt.Sprintf("%s: matches %d of oneOf", at, matches)}
+		}
+	}
+
+	var errs []string
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected object but got %v", at, value)}
+		}
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, ok := object[name.(string)]; !ok {
+					errs = append(errs, fmt.Sprintf("%s: missing required property %s", at, name))
+				}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		additional, _ := schema["additionalProperties"].(map[string]interface{})
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := properties[name]; ok {
+				errs = append(errs, validateSchema(root, property.(map[string]interface{}), object[name], at+"."+name)...)
+			} else if additional != nil {
+				errs = append(errs, validateSchema(root, additional, object[name], at+"."+name)...)
+			} else {
+				errs = append(errs, fmt.Sprintf("%s: property %s is not declared", at, name))
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected array but got %v", at, value)}
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range array {
+				errs = append(errs, validateSchema(root, items, item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			errs = append(errs, fmt.Sprintf("%s: expected string but got %v", at, value))
+		}
+	case "integer":
+		if number, ok := value.(float64); !ok || number != float64(int64(number)) {
+			errs = append(errs, fmt.Sprintf("%s: expected integer but got %v", at, value))
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			errs = append(errs, fmt.Sprintf("%s: expected number but got %v", at, value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			errs = append(errs, fmt.Sprintf("%s: expected boolean but got %v", at, value))
+		}
+	case "null":
+		if value != nil {
+			errs = append(errs, fmt.Sprintf("%s: expected null but got %v", at, value))
+		}
+	}
+
+	return errs
+}
+
+func TestPackageSummariesJSONSchema(t *testing.T) {
+	schema := loadSummarySchema(t)
+
+	testCases := []struct {
+		name             string
+		relevantPackages []string
+		paths            bool
+	}{
+		{name: "no packages"},
+		{name: "packages", relevantPackages: []string{"repo/lib/b", "repo/util", "fmt"}},
+		{name: "packages with paths", relevantPackages: []string{"repo/lib/b", "repo/util", "fmt"}, paths: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("case=%s", tc.name), func(t *testing.T) {
+			relevantPackages := make(lib.StringSet)
+			relevantPackages.Add(tc.relevantPackages...)
+			d := &diff{
+				summary: Summary{
+					SchemaVersion:  SchemaVersion,
+					RootImportPath: "repo/cmd",
+					SHA:            "0123456789abcdef0123456789abcdef01234567",
+					Packages:       []*Package{},
+					Commits:        []*Commit{},
+				},
+				graph:               testGraphSummary().Graph,
+				relevantPackages:    relevantPackages,
+				changedPackageFiles: map[string][]string{"repo/lib/b": {"lib/b/b.go"}, "repo/util": {"util/util.go"}},
+			}
+
+			if err := d.createPackageSummaries(tc.paths); err != nil {
+				t.Fatal(err)
+			}
+			if tc.paths {
+				if err := d.determinePaths(0, 0); err != nil {
+					t.Fatal(err)
+				}
+			}
+			d.determineRelevantFiles()
+			d.determineIssues()
+			d.summary.Bump = SemverBump(d.summary.Commits)
+
+			body, err := json.Marshal(&d.summary)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var value map[string]interface{}
+			if err := json.Unmarshal(body, &value); err != nil {
+				t.Fatal(err)
+			}
+			arrays := []interface{}{value["packages"], value["commits"], value["files"]}
+			for _, pkg := range value["packages"].([]interface{}) {
+				arrays = append(arrays, pkg.(map[string]interface{})["files"])
+			}
+			for _, array := range arrays {
+				if _, ok := array.([]interface{}); !ok {
+					t.Fatalf("Expected arrays of packages, commits and files but got %s", body)
+				}
+			}
+			if errs := validateSchema(schema, schema, value, "$"); len(errs) > 0 {
+				t.Fatalf("JSON of summary does not match the schema:\n%s", strings.Join(errs, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+)
+
+// Explanation describes how a root package reaches a target package.
+type Explanation struct {
+	RootImportPath string        `json:"rootImportPath"`
+	Target         string        `json:"target"`
+	Chains         [][]string    `json:"chains"`  // Import chains from the root to the target, shortest first
+	Imports        []*ImportEdge `json:"imports"` // Imports made along the chains, in the order they first appear
+}
+
+// ImportEdge describes where one package imports another.
+type ImportEdge struct {
+	From  string        `json:"from"`
+	To    string        `json:"to"`
+	Sites []*ImportSite `json:"sites"`
+}
+
+// ImportSite is an import statement, or a //go:generate directive running the imported package.
+type ImportSite struct {
+	File string `json:"file"` // File path, prefixed by the import path of its package
+	Line int    `json:"line"`
+	Kind string `json:"kind"` // One of importer.ImportProduction, importer.ImportTest or importer.ImportGenerate
+}
+
+// WhyOptions control how Why searches for import chains.
+type WhyOptions struct {
+	MaxChains      int  // If positive, at most this many import chains are returned.
+	MaxChainLength int  // If positive, import chains include at most this many packages.
+	Generate       bool // Treat packages run by //go:generate directives as imports.
+}
+
+// Why explains how the root package reaches the target package: every import chain between them,
+// and which files along each chain contain the import.
+func Why(importPath, target string, opts WhyOptions) (*Explanation, error) {
+	_, graph, err := recursiveDeps(importPath, opts.Generate)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := graph.Packages[target]; !ok {
+		return nil, fmt.Errorf("Package `%s` is not reachable from `%s`", target, importPath)
+	}
+
+	paths, err := graph.AllPaths(importPath, target, opts.MaxChains, opts.MaxChainLength)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &Explanation{RootImportPath: importPath, Target: target, Chains: [][]string{}, Imports: []*ImportEdge{}}
+	edges := make(map[[2]string]bool)
+	for _, chain := range paths {
+		explanation.Chains = append(explanation.Chains, chain)
+
+		for i := 1; i < len(chain); i++ {
+			from, to := chain[i-1], chain[i]
+			if edges[[2]string{from, to}] {
+				continue
+			}
+			edges[[2]string{from, to}] = true
+
+			pkg := graph.Packages[from]
+			sites, err := pkg.ImportSites(to)
+			if err != nil {
+				return nil, err
+			}
+
+			edge := &ImportEdge{From: from, To: to, Sites: []*ImportSite{}}
+			for _, site := range sites {
+				edge.Sites = append(edge.Sites, &ImportSite{
+					File: path.Join(pkg.ImportPath, filepath.ToSlash(filepath.Base(site.File))),
+					Line: site.Line,
+					Kind: site.Kind,
+				})
+			}
+			explanation.Imports = append(explanation.Imports, edge)
+		}
+	}
+
+	return explanation, nil
+}
+
+// Import returns where one package in the explanation's chains imports another, if it does.
+func (e *Explanation) Import(from, to string) *ImportEdge {
+	for _, edge := range e.Imports {
+		if edge.From == from && edge.To == to {
+			return edge
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/alecholmes/tdiff/app"
+	"github.com/alecholmes/tdiff/importer"
+)
+
+// check runs the "check" command, which reports violations of the config's dependency policies and
+// layers, and directory cycles, introduced after a SHA. It exits with status 4 if there are any.
+func check(args []string) {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	packageFlag := flags.String("package", "", "Root package; policies apply to the packages it reaches")
+	shaFlag := flags.String("sha", "", "Git SHA after which violations are reported (exclusive); violations at this SHA are ignored")
+	configFlag := flags.String("config", "", "Config file to use; defaults to "+app.DefaultConfigFile+" at the root of the Git repository, if it exists")
+	generateFlag := flags.Bool("generate", false, "If set, packages run by //go:generate directives are treated as imports")
+	jsonFlag := flags.Bool("json", false, "If set, the violations and cycles are printed as JSON")
+	verboseFlag := flags.Bool("verbose", false, "If set, log verbose debugging information")
+	flags.Parse(args)
+
+	if len(*packageFlag) == 0 || len(*shaFlag) == 0 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	logger := app.NoLogging
+	if *verboseFlag {
+		logger = log.Printf
+	}
+
+	differ := app.NewDiffer(os.Getenv("GOPATH"), importer.DefaultRecursiveImport, false, false, logger)
+	summary, err := differ.Diff(*packageFlag, *shaFlag, app.Options{
+		Generate:   *generateFlag,
+		Policies:   true,
+		ConfigFile: *configFlag,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *jsonFlag {
+		body, err := json.MarshalIndent(struct {
+			Violations      []*app.PolicyViolation `json:"violations"`
+			LayerViolations []*app.LayerViolation  `json:"layerViolations"`
+			Cycles          []*app.DirectoryCycle  `json:"cycles"`
+		}{summary.Violations, summary.LayerViolations, summary.Cycles}, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(body))
+	} else {
+		for _, violation := range summary.Violations {
+			fmt.Printf("%s: %s imports %s\n", violation.Policy, violation.Package, violation.Import)
+			fmt.Printf("  %s\n", strings.Join(violation.Chain, " > "))
+			if len(violation.File) > 0 {
+				fmt.Printf("  %s:%d\n", violation.File, violation.Line)
+			}
+		}
+		for _, violation := range summary.LayerViolations {
+			fmt.Printf("layer %s: %s imports %s of layer %s\n", violation.PackageLayer, violation.Package, violation.Import, violation.ImportLayer)
+			if len(violation.File) > 0 {
+				fmt.Printf("  %s:%d\n", violation.File, violation.Line)
+			}
+		}
+		for _, cycle := range summary.Cycles {
+			fmt.Printf("cycle: %s\n", strings.Join(cycle.Chain, " > "))
+			for _, dirImport := range cycle.Imports {
+				if len(dirImport.File) > 0 {
+					fmt.Printf("  %s imports %s (%s:%d)\n", dirImport.From, dirImport.To, dirImport.File, dirImport.Line)
+				} else {
+					fmt.Printf("  %s imports %s\n", dirImport.From, dirImport.To)
+				}
+			}
+		}
+	}
+
+	if len(summary.Violations) > 0 || len(summary.LayerViolations) > 0 || len(summary.Cycles) > 0 {
+		os.Exit(4)
+	}
+}
@@ -0,0 +1,160 @@
+package importer
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CgoInputs returns the absolute paths of the non-Go files that are compiled or linked into
+// the package: its C, C++, Objective-C, assembly, header and syso files, along with any
+// headers they include from other directories.
+//
+// Quoted includes are resolved relative to the including file and then to the -I directories
+// of the package's #cgo CFLAGS, CPPFLAGS and CXXFLAGS directives and the CGO_CFLAGS,
+// CGO_CPPFLAGS and CGO_CXXFLAGS environment variables. Angle bracket includes are only resolved
+// against the -I directories. Includes are followed recursively, and headers that can't be
+// found (e.g. system headers) are ignored.
+func (p *Package) CgoInputs() []string {
+	var sources []string
+	for _, files := range [][]string{p.CgoFiles, p.CFiles, p.CXXFiles, p.MFiles, p.HFiles, p.SFiles, p.SysoFiles} {
+		for _, file := range files {
+			sources = append(sources, filepath.Join(p.Dir, file))
+		}
+	}
+	if len(sources) == 0 {
+		return nil
+	}
+
+	includeDirs := p.cgoIncludeDirs()
+	visited := make(map[string]bool)
+	var inputs []string
+	for queue := sources; len(queue) > 0; queue = queue[1:] {
+		file := filepath.Clean(queue[0])
+		if visited[file] {
+			continue
+		}
+		visited[file] = true
+
+		// Go files with cgo preambles are scanned for includes, but are not inputs themselves.
+		if !strings.HasSuffix(file, ".go") {
+			inputs = append(inputs, file)
+		}
+		if strings.HasSuffix(file, ".syso") {
+			continue
+		}
+
+		for _, include := range scanIncludes(file) {
+			if resolved := resolveInclude(include, filepath.Dir(file), includeDirs); len(resolved) > 0 {
+				queue = append(queue, resolved)
+			}
+		}
+	}
+	sort.Strings(inputs)
+
+	return inputs
+}
+
+// cgoIncludeDirs returns the absolute -I directories used when compiling the package's cgo sources.
+// Relative directories are relative to the package directory.
+func (p *Package) cgoIncludeDirs() []string {
+	var flags []string
+	flags = append(flags, p.CgoCFLAGS...)
+	flags = append(flags, p.CgoCPPFLAGS...)
+	flags = append(flags, p.CgoCXXFLAGS...)
+	for _, env := range []string{"CGO_CFLAGS", "CGO_CPPFLAGS", "CGO_CXXFLAGS"} {
+		flags = append(flags, strings.Fields(os.Getenv(env))...)
+	}
+
+	var dirs []string
+	for i := 0; i < len(flags); i++ {
+		var dir string
+		if flags[i] == "-I" && i+1 < len(flags) {
+			i++
+			dir = flags[i]
+		} else if strings.HasPrefix(flags[i], "-I") {
+			dir = flags[i][2:]
+		} else {
+			continue
+		}
+
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(p.Dir, dir)
+		}
+		dirs = append(dirs, dir)
+	}
+
+	return dirs
+}
+
+// include is a header named by an #include directive.
+type include struct {
+	name   string
+	quoted bool // True for #include "name", false for #include <name>
+}
+
+// scanIncludes returns the headers included by a source file.
+// Unreadable files have no includes.
+func scanIncludes(file string) []include {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var includes []include
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// Go files with cgo preambles have directives inside of comments.
+		line = strings.TrimSpace(strings.TrimPrefix(line, "//"))
+		if !strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(line[1:])
+		if !strings.HasPrefix(line, "include") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "include"))
+
+		if len(line) < 2 {
+			continue
+		}
+		if end := strings.IndexByte(line[1:], '"'); line[0] == '"' && end >= 0 {
+			includes = append(includes, include{name: line[1 : end+1], quoted: true})
+		} else if end := strings.IndexByte(line[1:], '>'); line[0] == '<' && end >= 0 {
+			includes = append(includes, include{name: line[1 : end+1]})
+		}
+	}
+
+	return includes
+}
+
+// resolveInclude returns the path of an included header, or an empty string if it can't be found.
+func resolveInclude(inc include, fromDir string, includeDirs []string) string {
+	if filepath.IsAbs(inc.name) {
+		if fileExists(inc.name) {
+			return inc.name
+		}
+		return ""
+	}
+
+	dirs := includeDirs
+	if inc.quoted {
+		dirs = append([]string{fromDir}, includeDirs...)
+	}
+	for _, dir := range dirs {
+		if candidate := filepath.Join(dir, inc.name); fileExists(candidate) {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+func fileExists(file string) bool {
+	info, err := os.Stat(file)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,36 @@
+package importer
+
+import (
+	"go/build"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCgoInputs(t *testing.T) {
+	testdata, err := filepath.Abs("testdata/cgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkg := &Package{
+		Package: &build.Package{
+			Dir:       filepath.Join(testdata, "pkg"),
+			CgoFiles:  []string{"cgo.go"},
+			CFiles:    []string{"a.c"},
+			HFiles:    []string{"local.h"},
+			CgoCFLAGS: []string{"-I", "../include"},
+		},
+	}
+
+	expected := []string{
+		filepath.Join(testdata, "include/nested/deep.h"),
+		filepath.Join(testdata, "include/preamble.h"),
+		filepath.Join(testdata, "include/shared.h"),
+		filepath.Join(testdata, "pkg/a.c"),
+		filepath.Join(testdata, "pkg/local.h"),
+	}
+	if actual := pkg.CgoInputs(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected inputs %v but got %v", expected, actual)
+	}
+}
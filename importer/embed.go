@@ -0,0 +1,56 @@
+package importer
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// EmbedsFile returns true if the package, including its tests, embeds the given file
+// through a //go:embed directive. The file must be an absolute path.
+func (p *Package) EmbedsFile(file string) bool {
+	rel, err := filepath.Rel(p.Dir, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	for _, patterns := range [][]string{p.EmbedPatterns, p.TestEmbedPatterns, p.XTestEmbedPatterns} {
+		for _, pattern := range patterns {
+			if embedPatternMatches(pattern, rel) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// embedPatternMatches returns true if a //go:embed pattern matches a file path relative to the package directory.
+// A pattern matching a directory embeds the files within it recursively, except for files whose
+// names begin with '.' or '_' unless the pattern has the "all:" prefix.
+func embedPatternMatches(pattern, file string) bool {
+	all := strings.HasPrefix(pattern, "all:")
+	pattern = strings.TrimPrefix(pattern, "all:")
+
+	if matched, _ := path.Match(pattern, file); matched {
+		return true
+	}
+
+	for dir := path.Dir(file); dir != "."; dir = path.Dir(dir) {
+		if matched, _ := path.Match(pattern, dir); !matched {
+			continue
+		}
+		if all {
+			return true
+		}
+		for _, name := range strings.Split(strings.TrimPrefix(file, dir+"/"), "/") {
+			if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,34 @@
+package importer
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEmbedPatternMatches(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		file    string
+		matches bool
+	}{
+		{pattern: "a.txt", file: "a.txt", matches: true},
+		{pattern: "*.txt", file: "a.txt", matches: true},
+		{pattern: "*.txt", file: "static/a.txt", matches: false},
+		{pattern: "static", file: "static/a.txt", matches: true},
+		{pattern: "static", file: "static/nested/a.txt", matches: true},
+		{pattern: "static", file: "static/.hidden", matches: false},
+		{pattern: "static", file: "static/_nested/a.txt", matches: false},
+		{pattern: "all:static", file: "static/_nested/a.txt", matches: true},
+		{pattern: "static/.hidden", file: "static/.hidden", matches: true},
+		{pattern: "stat*", file: "static/a.txt", matches: true},
+		{pattern: "static", file: "other/a.txt", matches: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("pattern=%s file=%s", tc.pattern, tc.file), func(t *testing.T) {
+			if actual := embedPatternMatches(tc.pattern, tc.file); actual != tc.matches {
+				t.Errorf("Expected %v but got %v", tc.matches, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// GenerateDirective is a //go:generate directive in one of a package's Go files.
+type GenerateDirective struct {
+	File string   // Absolute path of the file containing the directive
+	Line int      // Line number of the directive
+	Args []string // Command and its arguments, e.g. [go run ./gen -out gen.go]
+}
+
+// GenerateDirectives returns the //go:generate directives in all of the package's Go files,
+// including test files and files excluded by build constraints.
+func (p *Package) GenerateDirectives() ([]GenerateDirective, error) {
+	var directives []GenerateDirective
+	for _, files := range [][]string{p.GoFiles, p.CgoFiles, p.TestGoFiles, p.XTestGoFiles, p.IgnoredGoFiles} {
+		for _, name := range files {
+			fileDirectives, err := scanGenerateDirectives(filepath.Join(p.Dir, name))
+			if err != nil {
+				return nil, err
+			}
+			directives = append(directives, fileDirectives...)
+		}
+	}
+
+	return directives, nil
+}
+
+// Run returns what a "go run" directive runs: either a package import path, resolved relative
+// to the given import path of the package containing the directive, or a list of Go files,
+// relative to the package directory. Both are empty if the directive doesn't use "go run",
+// or if it runs a versioned module package (e.g. pkg@v1.2.3).
+func (g GenerateDirective) Run(importPath string) (string, []string) {
+	if len(g.Args) < 2 || g.Args[0] != "go" || g.Args[1] != "run" {
+		return "", nil
+	}
+
+	// Skip flags to go run
+	args := g.Args[2:]
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return "", nil
+	}
+
+	if strings.HasSuffix(args[0], ".go") {
+		var files []string
+		for _, arg := range args {
+			if !strings.HasSuffix(arg, ".go") {
+				break
+			}
+			files = append(files, arg)
+		}
+		return "", files
+	}
+
+	if strings.Contains(args[0], "@") {
+		return "", nil
+	}
+	if args[0] == "." || strings.HasPrefix(args[0], "./") || strings.HasPrefix(args[0], "../") {
+		return path.Join(importPath, args[0]), nil
+	}
+
+	return args[0], nil
+}
+
+// Tool returns the name of the generator a directive runs: the package or first file for
+// "go run" directives, and the command otherwise.
+func (g GenerateDirective) Tool(importPath string) string {
+	if runPackage, runFiles := g.Run(importPath); len(runPackage) > 0 {
+		return runPackage
+	} else if len(runFiles) > 0 {
+		return path.Join(importPath, runFiles[0])
+	}
+
+	return g.Args[0]
+}
+
+// Inputs returns the absolute paths of existing files named by the directive's arguments,
+// such as templates and schemas. Arguments are resolved relative to the given directory,
+// and the values of flags like -template=file are also considered.
+func (g GenerateDirective) Inputs(dir string) []string {
+	var inputs []string
+	for _, arg := range g.Args[1:] {
+		candidates := []string{arg}
+		if i := strings.Index(arg, "="); strings.HasPrefix(arg, "-") && i >= 0 {
+			candidates = []string{arg[i+1:]}
+		}
+
+		for _, candidate := range candidates {
+			if len(candidate) == 0 || strings.HasPrefix(candidate, "-") {
+				continue
+			}
+			if !filepath.IsAbs(candidate) {
+				candidate = filepath.Join(dir, candidate)
+			}
+			if fileExists(candidate) {
+				inputs = append(inputs, filepath.Clean(candidate))
+			}
+		}
+	}
+
+	return inputs
+}
+
+// scanGenerateDirectives returns the //go:generate directives in a Go file.
+func scanGenerateDirectives(file string) ([]GenerateDirective, error) {
+	body, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseGenerateDirectives(file, body)
+}
+
+// ParseGenerateDirectives returns the //go:generate directives in the contents of a Go file.
+// The file name is recorded in the directives.
+func ParseGenerateDirectives(file string, body []byte) ([]GenerateDirective, error) {
+	var directives []GenerateDirective
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if !strings.HasPrefix(text, "//go:generate ") && !strings.HasPrefix(text, "//go:generate\t") {
+			continue
+		}
+
+		if args := splitGenerateArgs(text[len("//go:generate "):]); len(args) > 0 {
+			directives = append(directives, GenerateDirective{File: file, Line: line, Args: args})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return directives, nil
+}
+
+// RunImports returns the import paths, as written, of the packages a "go run" directive runs, given the
+// import path of the package containing the directive. For directives that run Go files, these are the
+// packages imported by those files, read by name relative to the package directory. Files that can't be
+// read or parsed are ignored.
+func (g GenerateDirective) RunImports(importPath string, readFile func(name string) ([]byte, error)) []string {
+	runPackage, runFiles := g.Run(importPath)
+
+	var importPaths []string
+	if len(runPackage) > 0 {
+		importPaths = append(importPaths, runPackage)
+	}
+	fset := token.NewFileSet()
+	for _, runFile := range runFiles {
+		body, err := readFile(runFile)
+		if err != nil {
+			continue
+		}
+		file, err := parser.ParseFile(fset, runFile, body, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+		for _, spec := range file.Imports {
+			if importPath, err := strconv.Unquote(spec.Path.Value); err == nil {
+				importPaths = append(importPaths, importPath)
+			}
+		}
+	}
+
+	return importPaths
+}
+
+// splitGenerateArgs splits a directive into words like go generate does: by whitespace,
+// except for double quoted strings, which are Go string literals.
+func splitGenerateArgs(line string) []string {
+	var args []string
+	for line = strings.TrimSpace(line); len(line) > 0; line = strings.TrimSpace(line) {
+		if line[0] == '"' {
+			if quoted, err := strconv.QuotedPrefix(line); err == nil {
+				if arg, err := strconv.Unquote(quoted); err == nil {
+					args = append(args, arg)
+					line = line[len(quoted):]
+					continue
+				}
+			}
+		}
+
+		end := strings.IndexAny(line, " \t")
+		if end < 0 {
+			end = len(line)
+		}
+		args = append(args, line[:end])
+		line = line[end:]
+	}
+
+	return args
+}
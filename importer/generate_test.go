@@ -0,0 +1,42 @@
+package importer
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateDirectiveRun(t *testing.T) {
+	testCases := []struct {
+		directive string
+		pkg       string
+		files     []string
+	}{
+		{directive: `go run ./gen -out x.go`, pkg: "a/b/gen"},
+		{directive: `go run ../tools/gen`, pkg: "a/tools/gen"},
+		{directive: `go run -tags=gen example.com/gen "quoted arg"`, pkg: "example.com/gen"},
+		{directive: `go run gen.go helper.go -out x.go`, files: []string{"gen.go", "helper.go"}},
+		{directive: `go run example.com/gen@v1.0.0`},
+		{directive: `stringer -type=Kind`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("directive=%s", tc.directive), func(t *testing.T) {
+			directive := GenerateDirective{Args: splitGenerateArgs(tc.directive)}
+			pkg, files := directive.Run("a/b")
+			if pkg != tc.pkg {
+				t.Errorf("Expected package %s but got %s", tc.pkg, pkg)
+			}
+			if !reflect.DeepEqual(tc.files, files) {
+				t.Errorf("Expected files %v but got %v", tc.files, files)
+			}
+		})
+	}
+}
+
+func TestSplitGenerateArgs(t *testing.T) {
+	expected := []string{"cmd", "-flag=value", "quoted arg", "last"}
+	if actual := splitGenerateArgs(`cmd  -flag=value	"quoted arg" last`); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected args %v but got %v", expected, actual)
+	}
+}
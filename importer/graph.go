@@ -26,11 +26,12 @@ type Package struct {
 	*build.Package
 
 	ImportVendoredPaths map[string]string // Package dep import path to real vendored import path
+	GenerateImports     []string          // Vendored import paths of packages run by //go:generate directives, if imported
 }
 
 // AllImports returns a list of all package import paths imported by the current package.
 // If vendored is true, the import paths returned will be the actual paths the Go build tool
-// resolves.
+// resolves. Packages run by //go:generate directives are included, if they were imported.
 func (p *Package) AllImports(vendored bool) []string {
 	importPaths := append(append(append([]string(nil), p.Imports...), p.TestImports...), p.XTestImports...)
 
@@ -43,7 +44,7 @@ func (p *Package) AllImports(vendored bool) []string {
 		}
 	}
 
-	return importPaths
+	return append(importPaths, p.GenerateImports...)
 }
 
 // PackageGraph represents all Go packages reachable from a single package.
@@ -78,16 +79,32 @@ func (p *PackageGraph) GoPackages() []*build.Package {
 	return packages
 }
 
+// WithoutTests returns a copy of the graph in which packages have no test imports. Imports of
+// packages run by //go:generate directives are kept. Packages only reachable through test imports
+// remain in the graph.
+func (p *PackageGraph) WithoutTests() *PackageGraph {
+	graph := &PackageGraph{Packages: make(map[string]*Package, len(p.Packages))}
+	for name, pkg := range p.Packages {
+		buildPkg := *pkg.Package
+		buildPkg.TestImports = nil
+		buildPkg.XTestImports = nil
+		graph.Packages[name] = &Package{
+			Package:             &buildPkg,
+			ImportVendoredPaths: pkg.ImportVendoredPaths,
+			GenerateImports:     pkg.GenerateImports,
+		}
+	}
+
+	return graph
+}
+
 // ShortestPath returns the shortest import path from one package to another.
 // If there is no path between the packages then nil is returned.
-// If there are multiple equally short paths, the path chosen to return is not deterministic.
+// If there are multiple equally short paths, the lexicographically smallest one is returned.
 // If either the from or to package does not exist, an error is returned.
 func (p *PackageGraph) ShortestPath(from, to string) (Path, error) {
-	if _, ok := p.Packages[from]; !ok {
-		return nil, fmt.Errorf("Import path `%s` does not exist in graph", from)
-	}
-	if _, ok := p.Packages[to]; !ok {
-		return nil, fmt.Errorf("Import path `%s` does not exist in graph", to)
+	if err := p.checkExists(from, to); err != nil {
+		return nil, err
 	}
 
 	visited := make(map[string]bool)
@@ -108,7 +125,8 @@ func (p *PackageGraph) ShortestPath(from, to string) (Path, error) {
 			return nil, fmt.Errorf("Unexpected: package `%s` does not exist in graph (path=%v)", lastImportPath, curPath)
 		}
 
-		for _, importName := range lastPkg.AllImports(true) {
+		// Visiting imports in order makes the first path found the lexicographically smallest.
+		for _, importName := range sortedImports(lastPkg) {
 			if !visited[importName] {
 				visited[importName] = true
 				pathQueue = append(pathQueue, curPath.Append(importName))
@@ -118,3 +136,149 @@ func (p *PackageGraph) ShortestPath(from, to string) (Path, error) {
 
 	return nil, nil
 }
+
+// AllPaths returns the simple import paths from one package to another, ordered by length
+// and then lexicographically. At most maxPaths paths with at most maxLength packages each
+// are returned; zero means no limit. If there is no path between the packages then nil is returned.
+// If either the from or to package does not exist, an error is returned.
+func (p *PackageGraph) AllPaths(from, to string, maxPaths, maxLength int) ([]Path, error) {
+	if err := p.checkExists(from, to); err != nil {
+		return nil, err
+	}
+	if maxLength <= 0 || maxLength > len(p.Packages) {
+		maxLength = len(p.Packages)
+	}
+
+	// Distance of each package to the target, used to prune paths that can't reach it in time.
+	distances := map[string]int{to: 0}
+	importers := p.importers()
+	queue := []string{to}
+	for len(queue) > 0 {
+		importPath := queue[0]
+		queue = queue[1:]
+		for _, importer := range importers[importPath] {
+			if _, ok := distances[importer]; !ok {
+				distances[importer] = distances[importPath] + 1
+				queue = append(queue, importer)
+			}
+		}
+	}
+
+	var paths []Path
+	onPath := make(map[string]bool)
+	var walk func(path Path, remaining int) bool
+	walk = func(path Path, remaining int) bool {
+		last := path[len(path)-1]
+		if last == to {
+			if remaining == 0 {
+				paths = append(paths, path)
+			}
+			return maxPaths <= 0 || len(paths) < maxPaths
+		}
+
+		onPath[last] = true
+		defer delete(onPath, last)
+		for _, importName := range sortedImports(p.Packages[last]) {
+			if distance, ok := distances[importName]; !ok || onPath[importName] || distance > remaining-1 {
+				continue
+			}
+			if !walk(path.Append(importName), remaining-1) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	// Searching for paths of each length in turn orders them by length.
+	if distance, ok := distances[from]; ok {
+		for imports := distance; imports < maxLength; imports++ {
+			if !walk(Path{from}, imports) {
+				break
+			}
+		}
+	}
+
+	return paths, nil
+}
+
+// Importers returns the sorted import paths of packages in the graph that directly import a package,
+// including test imports. If the package does not exist, an error is returned.
+func (p *PackageGraph) Importers(importPath string) ([]string, error) {
+	if err := p.checkExists(importPath); err != nil {
+		return nil, err
+	}
+
+	return p.importers()[importPath], nil
+}
+
+// Dependents returns the sorted import paths of packages in the graph that transitively import a package,
+// including test imports. The package itself is not included, even if it is part of an import cycle.
+// If the package does not exist, an error is returned.
+func (p *PackageGraph) Dependents(importPath string) ([]string, error) {
+	if err := p.checkExists(importPath); err != nil {
+		return nil, err
+	}
+
+	importers := p.importers()
+	visited := map[string]bool{importPath: true}
+	var dependents []string
+	queue := []string{importPath}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, importingPath := range importers[current] {
+			if !visited[importingPath] {
+				visited[importingPath] = true
+				dependents = append(dependents, importingPath)
+				queue = append(queue, importingPath)
+			}
+		}
+	}
+	sort.Strings(dependents)
+
+	return dependents, nil
+}
+
+// importers returns the sorted direct importers of every package in the graph.
+func (p *PackageGraph) importers() map[string][]string {
+	names := make([]string, 0, len(p.Packages))
+	for name := range p.Packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	importers := make(map[string][]string)
+	for _, name := range names {
+		for _, importName := range sortedImports(p.Packages[name]) {
+			importers[importName] = append(importers[importName], name)
+		}
+	}
+
+	return importers
+}
+
+func (p *PackageGraph) checkExists(importPaths ...string) error {
+	for _, importPath := range importPaths {
+		if _, ok := p.Packages[importPath]; !ok {
+			return fmt.Errorf("Import path `%s` does not exist in graph", importPath)
+		}
+	}
+
+	return nil
+}
+
+// sortedImports returns the sorted, unique vendored import paths of a package.
+func sortedImports(pkg *Package) []string {
+	var imports []string
+	seen := make(map[string]bool)
+	for _, importName := range pkg.AllImports(true) {
+		if !seen[importName] {
+			seen[importName] = true
+			imports = append(imports, importName)
+		}
+	}
+	sort.Strings(imports)
+
+	return imports
+}
@@ -7,31 +7,35 @@ import (
 	"testing"
 )
 
-func TestShortestPast(t *testing.T) {
-	addFakePackage := func(graph *PackageGraph, name string, imports ...string) *Package {
-		pkg := &Package{
-			Package: &build.Package{
-				ImportPath: name,
-				Imports:    imports,
-			},
-			ImportVendoredPaths: make(map[string]string),
-		}
+func addFakePackage(graph *PackageGraph, name string, imports ...string) *Package {
+	pkg := &Package{
+		Package: &build.Package{
+			ImportPath: name,
+			Imports:    imports,
+		},
+		ImportVendoredPaths: make(map[string]string),
+	}
 
-		for _, importPath := range imports {
-			pkg.ImportVendoredPaths[importPath] = importPath
-		}
+	for _, importPath := range imports {
+		pkg.ImportVendoredPaths[importPath] = importPath
+	}
 
-		graph.Packages[name] = pkg
+	graph.Packages[name] = pkg
 
-		return pkg
-	}
+	return pkg
+}
 
+func TestShortestPast(t *testing.T) {
 	// A -> [B, G]
 	// B -> [G]
 	// G -> [D]
 	// D -> []
 	// X -> [B, Y]
 	// Y -> []
+	// M -> [O, N]
+	// N -> [P]
+	// O -> [P]
+	// P -> []
 	graph := &PackageGraph{Packages: make(map[string]*Package)}
 	addFakePackage(graph, "A", "B", "G")
 	addFakePackage(graph, "B", "G")
@@ -39,6 +43,10 @@ func TestShortestPast(t *testing.T) {
 	addFakePackage(graph, "D")
 	addFakePackage(graph, "X", "Y", "B")
 	addFakePackage(graph, "Y")
+	addFakePackage(graph, "M", "O", "N")
+	addFakePackage(graph, "N", "P")
+	addFakePackage(graph, "O", "P")
+	addFakePackage(graph, "P")
 
 	testCases := []struct {
 		from string
@@ -50,6 +58,7 @@ func TestShortestPast(t *testing.T) {
 		{from: "A", to: "G", path: []string{"A", "G"}},
 		{from: "A", to: "D", path: []string{"A", "G", "D"}},
 		{from: "X", to: "A", path: nil},
+		{from: "M", to: "P", path: []string{"M", "N", "P"}},
 	}
 
 	for _, tc := range testCases {
@@ -71,3 +80,145 @@ func TestShortestPast(t *testing.T) {
 		t.Errorf("Expected error but got none")
 	}
 }
+
+func TestAllPaths(t *testing.T) {
+	// A -> [B, C, D]
+	// B -> [D, E]
+	// C -> [B, E]
+	// D -> [E]
+	// E -> [A]
+	graph := &PackageGraph{Packages: make(map[string]*Package)}
+	addFakePackage(graph, "A", "B", "C", "D")
+	addFakePackage(graph, "B", "D", "E")
+	addFakePackage(graph, "C", "B", "E")
+	addFakePackage(graph, "D", "E")
+	addFakePackage(graph, "E", "A")
+
+	testCases := []struct {
+		from      string
+		to        string
+		maxPaths  int
+		maxLength int
+		paths     []Path
+	}{
+		{from: "A", to: "A", paths: []Path{{"A"}}},
+		{from: "A", to: "E", paths: []Path{
+			{"A", "B", "E"},
+			{"A", "C", "E"},
+			{"A", "D", "E"},
+			{"A", "B", "D", "E"},
+			{"A", "C", "B", "E"},
+			{"A", "C", "B", "D", "E"},
+		}},
+		{from: "A", to: "E", maxPaths: 2, paths: []Path{{"A", "B", "E"}, {"A", "C", "E"}}},
+		{from: "A", to: "E", maxLength: 3, paths: []Path{{"A", "B", "E"}, {"A", "C", "E"}, {"A", "D", "E"}}},
+		{from: "A", to: "E", maxLength: 2, paths: nil},
+		{from: "E", to: "B", paths: []Path{{"E", "A", "B"}, {"E", "A", "C", "B"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("from=%s to=%s maxPaths=%d maxLength=%d", tc.from, tc.to, tc.maxPaths, tc.maxLength), func(t *testing.T) {
+			paths, err := graph.AllPaths(tc.from, tc.to, tc.maxPaths, tc.maxLength)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tc.paths, paths) {
+				t.Fatalf("Expected paths %v but got %v", tc.paths, paths)
+			}
+		})
+	}
+
+	if _, err := graph.AllPaths("A", "does not exist", 0, 0); err == nil {
+		t.Errorf("Expected error but got none")
+	}
+}
+
+func TestImporters(t *testing.T) {
+	graph := &PackageGraph{Packages: make(map[string]*Package)}
+	addFakePackage(graph, "A", "B", "C")
+	addFakePackage(graph, "B", "C")
+	addFakePackage(graph, "C")
+	addFakePackage(graph, "D", "B").TestImports = []string{"C"}
+
+	testCases := []struct {
+		importPath string
+		importers  []string
+	}{
+		{importPath: "A", importers: nil},
+		{importPath: "B", importers: []string{"A", "D"}},
+		{importPath: "C", importers: []string{"A", "B", "D"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("importPath=%s", tc.importPath), func(t *testing.T) {
+			importers, err := graph.Importers(tc.importPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tc.importers, importers) {
+				t.Fatalf("Expected importers %v but got %v", tc.importers, importers)
+			}
+		})
+	}
+}
+
+func TestDependents(t *testing.T) {
+	// A -> [B, C]
+	// B -> [C]
+	// C -> [D]
+	// D -> [C]
+	// E -> []
+	graph := &PackageGraph{Packages: make(map[string]*Package)}
+	addFakePackage(graph, "A", "B", "C")
+	addFakePackage(graph, "B", "C")
+	addFakePackage(graph, "C", "D")
+	addFakePackage(graph, "D", "C")
+	addFakePackage(graph, "E")
+
+	testCases := []struct {
+		importPath string
+		dependents []string
+	}{
+		{importPath: "A", dependents: nil},
+		{importPath: "B", dependents: []string{"A"}},
+		{importPath: "C", dependents: []string{"A", "B", "D"}},
+		{importPath: "D", dependents: []string{"A", "B", "C"}},
+		{importPath: "E", dependents: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("importPath=%s", tc.importPath), func(t *testing.T) {
+			dependents, err := graph.Dependents(tc.importPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tc.dependents, dependents) {
+				t.Fatalf("Expected dependents %v but got %v", tc.dependents, dependents)
+			}
+		})
+	}
+}
+
+func TestWithoutTests(t *testing.T) {
+	graph := &PackageGraph{Packages: make(map[string]*Package)}
+	addFakePackage(graph, "A", "B")
+	addFakePackage(graph, "B")
+	addFakePackage(graph, "C")
+	addFakePackage(graph, "D")
+	a := graph.Packages["A"]
+	a.TestImports = []string{"C"}
+	a.XTestImports = []string{"C"}
+	a.GenerateImports = []string{"D"}
+
+	// Generators are kept, since they are run to build the package.
+	withoutTests := graph.WithoutTests()
+	if expected, actual := []string{"B", "D"}, withoutTests.Packages["A"].AllImports(true); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected imports %v but got %v", expected, actual)
+	}
+	if len(withoutTests.Packages) != 4 {
+		t.Fatalf("Expected 4 packages but got %d", len(withoutTests.Packages))
+	}
+	if expected, actual := []string{"B", "C", "C", "D"}, a.AllImports(true); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected original imports %v but got %v", expected, actual)
+	}
+}
@@ -3,7 +3,9 @@ package importer
 import (
 	"fmt"
 	"go/build"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -26,9 +28,32 @@ func RecursiveImport(importPath string, goPath string, buildContext *build.Conte
 	}, nil
 }
 
+// DefaultRecursiveImportWithGenerators is like DefaultRecursiveImport, but also imports packages
+// run by //go:generate directives. See RecursiveImportWithGenerators.
+func DefaultRecursiveImportWithGenerators(importPath string) (*PackageGraph, error) {
+	buildCtx := build.Default
+	return RecursiveImportWithGenerators(importPath, os.Getenv("GOPATH"), &buildCtx)
+}
+
+// RecursiveImportWithGenerators is like RecursiveImport, but also treats packages run with
+// "go run" by //go:generate directives as dependencies of the packages containing the directives.
+// They are recorded in Package.GenerateImports. Generators that cannot be imported are ignored.
+func RecursiveImportWithGenerators(importPath string, goPath string, buildContext *build.Context) (*PackageGraph, error) {
+	importer := newRecursiveImporter(goPath, buildContext)
+	importer.generators = true
+	if err := importer.importPackage(nil, importPath); err != nil {
+		return nil, err
+	}
+
+	return &PackageGraph{
+		Packages: importer.packages,
+	}, nil
+}
+
 type recursiveImporter struct {
 	goPath       string
 	buildContext *build.Context
+	generators   bool // Whether to import packages run by //go:generate directives
 	packages     map[string]*Package
 }
 
@@ -59,7 +84,43 @@ func (r *recursiveImporter) importPackage(parentPkg *Package, importPath string)
 		return err
 	}
 
-	return r.importAll(pkg, pkg.XTestImports)
+	if err := r.importAll(pkg, pkg.XTestImports); err != nil {
+		return err
+	}
+
+	if r.generators && !pkg.Goroot {
+		r.importGenerators(pkg)
+	}
+
+	return nil
+}
+
+// importGenerators imports the packages run by a package's //go:generate directives.
+// For directives that run Go files, the packages imported by those files are imported.
+func (r *recursiveImporter) importGenerators(pkg *Package) {
+	directives, err := pkg.GenerateDirectives()
+	if err != nil {
+		return
+	}
+
+	for _, directive := range directives {
+		for _, importPath := range generatorImports(pkg, directive) {
+			if err := r.importPackage(pkg, importPath); err != nil {
+				continue
+			}
+			if vendoredPath, ok := pkg.ImportVendoredPaths[importPath]; ok && !containsString(pkg.GenerateImports, vendoredPath) {
+				pkg.GenerateImports = append(pkg.GenerateImports, vendoredPath)
+			}
+		}
+	}
+}
+
+// generatorImports returns the import paths, as written, of the packages a //go:generate directive
+// of the package runs. For directives that run Go files, these are the packages imported by those files.
+func generatorImports(pkg *Package, directive GenerateDirective) []string {
+	return directive.RunImports(pkg.ImportPath, func(name string) ([]byte, error) {
+		return ioutil.ReadFile(filepath.Join(pkg.Dir, name))
+	})
 }
 
 func (r *recursiveImporter) importAll(parentPkg *Package, importPaths []string) error {
@@ -127,6 +188,16 @@ func (r *recursiveImporter) rawImport(importPath string) (*build.Package, error)
 	return pkg, nil
 }
 
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
 func packageImportPath(pkg *Package) string {
 	if pkg == nil {
 		return ""
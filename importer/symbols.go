@@ -0,0 +1,264 @@
+package importer
+
+import (
+	"fmt"
+	"go/ast"
+	goimporter "go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+
+	"github.com/alecholmes/tdiff/source"
+)
+
+// UsedSymbols type-checks the root package and the packages it reaches, and returns the
+// keys (see source.SymbolKey) of every package-level declaration the root package
+// transitively uses.
+//
+// All declarations in the root package are considered used, as are init functions and
+// package-level variables of every package the root reaches through non-test imports, since
+// they run at initialization even if the package is only blank imported. When a type is used
+// all of its methods are considered used, since they may be called through an interface.
+// If a package has type errors, its references can not be resolved, so its errors are logged
+// and all of its declarations are considered used. Packages in GOROOT are not analyzed, and
+// only non-test Go files are considered.
+func (p *PackageGraph) UsedSymbols(root string, logger func(string, ...interface{})) (map[string]bool, error) {
+	if _, ok := p.Packages[root]; !ok {
+		return nil, fmt.Errorf("Import path `%s` does not exist in graph", root)
+	}
+
+	analyzer := newSymbolAnalyzer(p)
+	var queue []symbolRef
+	for _, importPath := range p.productionReachable(root) {
+		symbols, err := analyzer.analyze(importPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if importPath == root || len(symbols.typeErrors) > 0 {
+			for _, typeErr := range symbols.typeErrors {
+				logger("Considering all declarations of %s used due to type error: %v", importPath, typeErr)
+			}
+			queue = append(queue, symbols.declared...)
+		} else {
+			queue = append(queue, symbols.initialized...)
+		}
+	}
+
+	used := make(map[string]bool)
+	for len(queue) > 0 {
+		ref := queue[0]
+		queue = queue[1:]
+
+		if used[ref.key] {
+			continue
+		}
+		used[ref.key] = true
+
+		symbols, err := analyzer.analyze(ref.importPath)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, symbols.uses[ref.key]...)
+		queue = append(queue, symbols.methods[ref.key]...)
+	}
+
+	return used, nil
+}
+
+// productionReachable returns the sorted import paths of the packages in the graph that are
+// reachable from the root through non-test imports, including the root.
+func (p *PackageGraph) productionReachable(root string) []string {
+	visited := map[string]bool{root: true}
+	queue := []string{root}
+	for len(queue) > 0 {
+		pkg, ok := p.Packages[queue[0]]
+		queue = queue[1:]
+		if !ok {
+			continue
+		}
+
+		for _, importPath := range pkg.Imports {
+			if vendored, ok := pkg.ImportVendoredPaths[importPath]; ok {
+				importPath = vendored
+			}
+			if !visited[importPath] {
+				visited[importPath] = true
+				queue = append(queue, importPath)
+			}
+		}
+	}
+
+	reachable := make([]string, 0, len(visited))
+	for importPath := range visited {
+		reachable = append(reachable, importPath)
+	}
+	sort.Strings(reachable)
+
+	return reachable
+}
+
+// symbolRef identifies a declaration along with the package it is declared in.
+type symbolRef struct {
+	importPath string
+	key        string
+}
+
+// packageSymbols describes the declarations of a single package and what they reference.
+type packageSymbols struct {
+	declared    []symbolRef            // All declarations in the package
+	initialized []symbolRef            // Declarations run at package initialization
+	uses        map[string][]symbolRef // Symbols referenced by each declaration, by key
+	methods     map[string][]symbolRef // Methods of each type, by type key
+	typeErrors  []error                // Errors type-checking the package, if any
+}
+
+type symbolAnalyzer struct {
+	graph    *PackageGraph
+	fset     *token.FileSet
+	importer types.Importer
+	packages map[string]*packageSymbols // Analyzed packages, by import path
+}
+
+func newSymbolAnalyzer(graph *PackageGraph) *symbolAnalyzer {
+	fset := token.NewFileSet()
+	return &symbolAnalyzer{
+		graph:    graph,
+		fset:     fset,
+		importer: goimporter.ForCompiler(fset, "source", nil),
+		packages: make(map[string]*packageSymbols),
+	}
+}
+
+// analyze type-checks a package and records the references made by each of its declarations.
+// Packages that are not in the graph or are in GOROOT have no recorded declarations.
+func (a *symbolAnalyzer) analyze(importPath string) (*packageSymbols, error) {
+	if symbols, ok := a.packages[importPath]; ok {
+		return symbols, nil
+	}
+
+	symbols := &packageSymbols{
+		uses:    make(map[string][]symbolRef),
+		methods: make(map[string][]symbolRef),
+	}
+	a.packages[importPath] = symbols
+
+	pkg, ok := a.graph.Packages[importPath]
+	if !ok || pkg.Goroot {
+		return symbols, nil
+	}
+
+	var files []*ast.File
+	for _, name := range append(append([]string(nil), pkg.GoFiles...), pkg.CgoFiles...) {
+		file, err := parser.ParseFile(a.fset, filepath.Join(pkg.Dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	// Type errors are recorded rather than failing, so that whatever could be resolved is still recorded.
+	info := &types.Info{Uses: make(map[*ast.Ident]types.Object)}
+	conf := types.Config{
+		Importer:    a.importer,
+		FakeImportC: true,
+		Error: func(err error) {
+			symbols.typeErrors = append(symbols.typeErrors, err)
+		},
+	}
+	conf.Check(importPath, a.fset, files, info)
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			for _, unit := range source.Units(decl) {
+				keys := source.DeclKeys(importPath, unit)
+				if len(keys) == 0 {
+					continue
+				}
+
+				var refs []symbolRef
+				ast.Inspect(unit, func(node ast.Node) bool {
+					if ident, ok := node.(*ast.Ident); ok {
+						if ref, ok := objectRef(info.Uses[ident]); ok {
+							refs = append(refs, ref)
+						}
+					}
+					return true
+				})
+
+				for _, key := range keys {
+					ref := symbolRef{importPath: importPath, key: key}
+					symbols.declared = append(symbols.declared, ref)
+					symbols.uses[key] = append(symbols.uses[key], refs...)
+				}
+
+				switch d := unit.(type) {
+				case *ast.FuncDecl:
+					if recv := source.ReceiverTypeName(d); len(recv) > 0 {
+						typeKey := source.SymbolKey(importPath, recv)
+						symbols.methods[typeKey] = append(symbols.methods[typeKey], symbolRef{importPath: importPath, key: keys[0]})
+					} else if d.Name.Name == "init" {
+						symbols.initialized = append(symbols.initialized, symbolRef{importPath: importPath, key: keys[0]})
+					}
+				case *ast.GenDecl:
+					if d.Tok == token.VAR {
+						for _, key := range keys {
+							symbols.initialized = append(symbols.initialized, symbolRef{importPath: importPath, key: key})
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return symbols, nil
+}
+
+// objectRef returns a reference to the package-level declaration of a type-checked object.
+// False is returned for objects that are not package-level declarations, such as
+// local variables, struct fields and builtins.
+func objectRef(obj types.Object) (symbolRef, bool) {
+	if obj == nil || obj.Pkg() == nil {
+		return symbolRef{}, false
+	}
+	importPath := obj.Pkg().Path()
+
+	switch o := obj.(type) {
+	case *types.Func:
+		o = o.Origin()
+		if recv := o.Type().(*types.Signature).Recv(); recv != nil {
+			typeName := namedTypeName(recv.Type())
+			if len(typeName) == 0 {
+				return symbolRef{}, false
+			}
+			return symbolRef{importPath: importPath, key: source.SymbolKey(importPath, typeName, o.Name())}, true
+		}
+	case *types.Var:
+		if o.IsField() {
+			return symbolRef{}, false
+		}
+	case *types.PkgName, *types.Label:
+		return symbolRef{}, false
+	}
+
+	if obj.Parent() != obj.Pkg().Scope() {
+		return symbolRef{}, false
+	}
+
+	return symbolRef{importPath: importPath, key: source.SymbolKey(importPath, obj.Name())}, true
+}
+
+// namedTypeName returns the name of a possibly pointer to named type.
+// An empty string is returned for unnamed types.
+func namedTypeName(typ types.Type) string {
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	if named, ok := typ.(*types.Named); ok {
+		return named.Obj().Name()
+	}
+
+	return ""
+}
@@ -0,0 +1,106 @@
+package importer
+
+import (
+	"fmt"
+	"go/build"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUsedSymbols(t *testing.T) {
+	const (
+		root   = "github.com/alecholmes/tdiff/importer/test_packages/symbols/root"
+		util   = "github.com/alecholmes/tdiff/importer/test_packages/symbols/util"
+		driver = "github.com/alecholmes/tdiff/importer/test_packages/symbols/driver"
+	)
+
+	graph, err := DefaultRecursiveImport(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	used, err := graph.UsedSymbols(root, t.Logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{
+		root + ".Run",
+		util + ".NewSet",
+		util + ".Set",
+		util + ".Set.Add",
+		util + ".Used",
+		util + ".helper",
+		util + ".init",
+		util + ".registry",
+		driver + ".init", // Blank imported packages are initialized
+		driver + ".register",
+		driver + ".drivers",
+	} {
+		if !used[key] {
+			t.Errorf("Expected %s to be used", key)
+		}
+	}
+
+	for _, key := range []string{
+		util + ".Unused",
+		util + ".First",
+		util + ".Second",
+		driver + ".Unregistered",
+	} {
+		if used[key] {
+			t.Errorf("Expected %s to be unused", key)
+		}
+	}
+
+	if _, err := graph.UsedSymbols("does not exist", t.Logf); err == nil {
+		t.Errorf("Expected error but got none")
+	}
+}
+
+func TestUsedSymbolsTypeErrors(t *testing.T) {
+	testdata, err := filepath.Abs("testdata/symbols")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	graph := &PackageGraph{Packages: map[string]*Package{
+		"example.com/root": {Package: &build.Package{
+			ImportPath: "example.com/root",
+			Dir:        filepath.Join(testdata, "root"),
+			GoFiles:    []string{"root.go"},
+			Imports:    []string{"example.com/broken"},
+		}},
+		"example.com/broken": {Package: &build.Package{
+			ImportPath: "example.com/broken",
+			Dir:        filepath.Join(testdata, "broken"),
+			GoFiles:    []string{"broken.go"},
+			Imports:    []string{"example.com/does/not/exist"},
+		}},
+	}}
+
+	var logs []string
+	used, err := graph.UsedSymbols("example.com/root", func(format string, args ...interface{}) {
+		logs = append(logs, fmt.Sprintf(format, args...))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The unresolved import leaves the references of the broken package unknown, so all of its
+	// declarations are used.
+	for _, key := range []string{"example.com/root.Run", "example.com/broken.Used", "example.com/broken.Other"} {
+		if !used[key] {
+			t.Errorf("Expected %s to be used", key)
+		}
+	}
+
+	logged := false
+	for _, log := range logs {
+		logged = logged || strings.Contains(log, "example.com/broken") && strings.Contains(log, "does/not/exist")
+	}
+	if !logged {
+		t.Errorf("Expected the type error of example.com/broken to be logged but got %v", logs)
+	}
+}
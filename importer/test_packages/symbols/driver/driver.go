@@ -0,0 +1,15 @@
+package driver
+
+var drivers []string
+
+func init() {
+	register("driver")
+}
+
+func register(name string) {
+	drivers = append(drivers, name)
+}
+
+func Unregistered() string {
+	return "unregistered"
+}
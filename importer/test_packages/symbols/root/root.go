@@ -0,0 +1,13 @@
+package root
+
+import (
+	_ "github.com/alecholmes/tdiff/importer/test_packages/symbols/driver"
+	"github.com/alecholmes/tdiff/importer/test_packages/symbols/util"
+)
+
+func Run() string {
+	set := util.NewSet()
+	set.Add("value")
+
+	return util.Used()
+}
@@ -0,0 +1,34 @@
+package util
+
+var registry = map[string]int{}
+
+func init() {
+	registry["util"] = len(registry)
+}
+
+type Set map[string]bool
+
+func (s Set) Add(value string) {
+	s[value] = true
+}
+
+func NewSet() Set {
+	return make(Set)
+}
+
+func Used() string {
+	return helper()
+}
+
+func Unused() string {
+	return "unused"
+}
+
+func helper() string {
+	return "helper"
+}
+
+const (
+	First = iota
+	Second
+)
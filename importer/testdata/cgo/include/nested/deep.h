@@ -0,0 +1 @@
+#define DEEP 1
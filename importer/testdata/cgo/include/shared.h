@@ -0,0 +1 @@
+#include "nested/deep.h"
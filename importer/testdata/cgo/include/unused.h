@@ -0,0 +1 @@
+#define UNUSED 1
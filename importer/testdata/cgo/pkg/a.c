@@ -0,0 +1,3 @@
+#include <stdio.h>
+#include "local.h"
+#include "shared.h"
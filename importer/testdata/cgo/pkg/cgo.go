@@ -0,0 +1,5 @@
+package pkg
+
+// #cgo CFLAGS: -I${SRCDIR}/../include
+// #include "preamble.h"
+import "C"
@@ -0,0 +1 @@
+#define LOCAL 1
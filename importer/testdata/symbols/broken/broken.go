@@ -0,0 +1,11 @@
+package broken
+
+import "example.com/does/not/exist"
+
+func Used() string {
+	return exist.Name
+}
+
+func Other() string {
+	return "other"
+}
@@ -0,0 +1,7 @@
+package root
+
+import "example.com/broken"
+
+func Run() string {
+	return broken.Used()
+}
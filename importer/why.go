@@ -0,0 +1,77 @@
+package importer
+
+import (
+	"go/token"
+	"sort"
+)
+
+// Kinds of ImportSite.
+const (
+	ImportProduction = "production" // Import by a non-test Go file
+	ImportTest       = "test"       // Import by a _test.go file, in the package or its external test package
+	ImportGenerate   = "generate"   // Package run by a //go:generate directive
+)
+
+// ImportSite is a place in a package's files where another package is imported.
+type ImportSite struct {
+	File string // Absolute path of the file
+	Line int
+	Kind string // One of ImportProduction, ImportTest or ImportGenerate
+}
+
+// ImportSites returns where the package imports another package, given by its vendored
+// import path, ordered by file and line. For packages run by //go:generate directives,
+// the directives are returned.
+func (p *Package) ImportSites(importPath string) ([]ImportSite, error) {
+	var sites []ImportSite
+	for _, positions := range []struct {
+		kind      string
+		positions map[string][]token.Position
+	}{
+		{ImportProduction, p.ImportPos},
+		{ImportTest, p.TestImportPos},
+		{ImportTest, p.XTestImportPos},
+	} {
+		for written, writtenPositions := range positions.positions {
+			if p.vendoredPath(written) != importPath {
+				continue
+			}
+			for _, position := range writtenPositions {
+				sites = append(sites, ImportSite{File: position.Filename, Line: position.Line, Kind: positions.kind})
+			}
+		}
+	}
+
+	if containsString(p.GenerateImports, importPath) {
+		directives, err := p.GenerateDirectives()
+		if err != nil {
+			return nil, err
+		}
+		for _, directive := range directives {
+			for _, written := range generatorImports(p, directive) {
+				if p.vendoredPath(written) == importPath {
+					sites = append(sites, ImportSite{File: directive.File, Line: directive.Line, Kind: ImportGenerate})
+					break
+				}
+			}
+		}
+	}
+
+	sort.Slice(sites, func(i, j int) bool {
+		if sites[i].File != sites[j].File {
+			return sites[i].File < sites[j].File
+		}
+		return sites[i].Line < sites[j].Line
+	})
+
+	return sites, nil
+}
+
+// vendoredPath returns the vendored import path of an import path as written in the package.
+func (p *Package) vendoredPath(importPath string) string {
+	if vendoredPath, ok := p.ImportVendoredPaths[importPath]; ok {
+		return vendoredPath
+	}
+
+	return importPath
+}
@@ -0,0 +1,48 @@
+package importer
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestImportSites(t *testing.T) {
+	graph, err := DefaultRecursiveImport("github.com/alecholmes/tdiff/importer/test_packages/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := graph.Packages["github.com/alecholmes/tdiff/importer/test_packages/a/aa/aaa"]
+
+	testCases := []struct {
+		importPath string
+		sites      []ImportSite
+	}{
+		{
+			importPath: "github.com/alecholmes/tdiff/importer/test_packages/a/aa/aaa/vendor/p",
+			sites: []ImportSite{
+				{File: filepath.Join(pkg.Dir, "aaa.go"), Line: 4, Kind: ImportProduction},
+				{File: filepath.Join(pkg.Dir, "aaa_test.go"), Line: 4, Kind: ImportTest},
+			},
+		},
+		{
+			importPath: "github.com/alecholmes/tdiff/importer/test_packages/b",
+			sites: []ImportSite{
+				{File: filepath.Join(pkg.Dir, "aaa_test.go"), Line: 6, Kind: ImportTest},
+			},
+		},
+		{importPath: "github.com/alecholmes/tdiff/importer/test_packages/vendor/p", sites: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("importPath=%s", tc.importPath), func(t *testing.T) {
+			sites, err := pkg.ImportSites(tc.importPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tc.sites, sites) {
+				t.Fatalf("Expected sites %v but got %v", tc.sites, sites)
+			}
+		})
+	}
+}
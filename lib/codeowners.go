@@ -0,0 +1,72 @@
+package lib
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// CodeOwnersFiles are the locations of a CODEOWNERS file relative to the root of a Git repository,
+// in the order they are looked up.
+var CodeOwnersFiles = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// CodeOwners are the owners of files in a repository, as declared by a CODEOWNERS file.
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+type codeOwnersRule struct {
+	pattern []string // Glob pattern split by path element; see MatchGlob
+	within  bool     // Whether the pattern also matches everything within a matching directory
+	owners  []string
+}
+
+// ParseCodeOwners parses the contents of a CODEOWNERS file. Each line is a gitignore style
+// pattern followed by the owners of matching files, and later lines take precedence.
+func ParseCodeOwners(body []byte) *CodeOwners {
+	codeOwners := &CodeOwners{}
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		pattern := strings.TrimSuffix(fields[0], "/")
+		// Patterns without a slash, other than a trailing one, match at any depth.
+		if !strings.Contains(pattern, "/") {
+			pattern = "**/" + pattern
+		}
+		pattern = strings.TrimPrefix(pattern, "/")
+		parts := strings.Split(pattern, "/")
+
+		codeOwners.rules = append(codeOwners.rules, codeOwnersRule{
+			pattern: parts,
+			// A trailing "*" only matches files directly within a directory, e.g. docs/*
+			within: parts[len(parts)-1] != "*",
+			owners: fields[1:],
+		})
+	}
+
+	return codeOwners
+}
+
+// Owners returns the owners of a file, relative to the root of the repository.
+// Nil is returned if the file has no owners.
+func (c *CodeOwners) Owners(file string) []string {
+	nameParts := strings.Split(file, "/")
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if matchGlobParts(c.rules[i].pattern, nameParts, c.rules[i].within) {
+			if len(c.rules[i].owners) == 0 {
+				return nil
+			}
+			return c.rules[i].owners
+		}
+	}
+
+	return nil
+}
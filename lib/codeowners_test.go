@@ -0,0 +1,44 @@
+package lib
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestCodeOwners(t *testing.T) {
+	codeOwners := ParseCodeOwners([]byte(`
+# Default owners
+*                  @org/platform
+
+*.go               @org/gophers # Go code
+/build/            @org/release
+docs/*             docs@example.com
+apps/**/config.yml @org/sre
+/vendor/
+`))
+
+	testCases := []struct {
+		file   string
+		owners []string
+	}{
+		{file: "README.md", owners: []string{"@org/platform"}},
+		{file: "main.go", owners: []string{"@org/gophers"}},
+		{file: "a/b/main.go", owners: []string{"@org/gophers"}},
+		{file: "build/main.go", owners: []string{"@org/release"}},
+		{file: "build/a/b.sh", owners: []string{"@org/release"}},
+		{file: "a/build/b.sh", owners: []string{"@org/platform"}},
+		{file: "docs/index.md", owners: []string{"docs@example.com"}},
+		{file: "docs/guides/index.md", owners: []string{"@org/platform"}},
+		{file: "apps/x/y/config.yml", owners: []string{"@org/sre"}},
+		{file: "vendor/lib/lib.go", owners: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("file=%s", tc.file), func(t *testing.T) {
+			if actual := codeOwners.Owners(tc.file); !reflect.DeepEqual(tc.owners, actual) {
+				t.Fatalf("Expected owners %v but got %v", tc.owners, actual)
+			}
+		})
+	}
+}
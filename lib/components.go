@@ -0,0 +1,69 @@
+package lib
+
+import "sort"
+
+// StronglyConnectedComponents returns the strongly connected components of a directed graph, given as
+// edges from each node to the nodes it points to. Each component is sorted, and components are ordered
+// by their first node. Nodes that only appear as edge targets are included.
+func StronglyConnectedComponents(edges map[string][]string) [][]string {
+	nodeSet := make(StringSet)
+	for from, tos := range edges {
+		nodeSet.Add(from)
+		nodeSet.Add(tos...)
+	}
+	nodes := nodeSet.Slice()
+	sort.Strings(nodes)
+
+	// Tarjan's algorithm
+	index := make(map[string]int)
+	lowLink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+
+	var visit func(node string)
+	visit = func(node string) {
+		index[node] = len(index)
+		lowLink[node] = index[node]
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for _, to := range edges[node] {
+			if _, ok := index[to]; !ok {
+				visit(to)
+				if lowLink[to] < lowLink[node] {
+					lowLink[node] = lowLink[to]
+				}
+			} else if onStack[to] && index[to] < lowLink[node] {
+				lowLink[node] = index[to]
+			}
+		}
+
+		if lowLink[node] == index[node] {
+			var component []string
+			for {
+				last := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[last] = false
+				component = append(component, last)
+				if last == node {
+					break
+				}
+			}
+			sort.Strings(component)
+			components = append(components, component)
+		}
+	}
+
+	for _, node := range nodes {
+		if _, ok := index[node]; !ok {
+			visit(node)
+		}
+	}
+
+	sort.Slice(components, func(i, j int) bool {
+		return components[i][0] < components[j][0]
+	})
+
+	return components
+}
@@ -0,0 +1,39 @@
+package lib
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	testCases := []struct {
+		edges      map[string][]string
+		components [][]string
+	}{
+		{edges: map[string][]string{}, components: nil},
+		{edges: map[string][]string{"a": {"b"}}, components: [][]string{{"a"}, {"b"}}},
+		{edges: map[string][]string{"a": {"b"}, "b": {"a"}}, components: [][]string{{"a", "b"}}},
+		{edges: map[string][]string{"a": {"a"}}, components: [][]string{{"a"}}},
+		{
+			// a -> b -> c -> a, c -> d -> e -> d, f
+			edges: map[string][]string{
+				"a": {"b"},
+				"b": {"c"},
+				"c": {"a", "d"},
+				"d": {"e"},
+				"e": {"d"},
+				"f": nil,
+			},
+			components: [][]string{{"a", "b", "c"}, {"d", "e"}, {"f"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("edges=%v", tc.edges), func(t *testing.T) {
+			if actual := StronglyConnectedComponents(tc.edges); !reflect.DeepEqual(tc.components, actual) {
+				t.Fatalf("Expected components %v but got %v", tc.components, actual)
+			}
+		})
+	}
+}
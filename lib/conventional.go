@@ -0,0 +1,52 @@
+package lib
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+)
+
+var (
+	conventionalSubject = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()]*)\))?(!)?: (.+)$`)
+	breakingFooter      = regexp.MustCompile(`^BREAKING[ -]CHANGE: `)
+)
+
+// ConventionalCommit is a commit message parsed according to Conventional Commits,
+// e.g. "feat(parser)!: support arrays". See https://www.conventionalcommits.org.
+type ConventionalCommit struct {
+	Type        string `json:"type"`            // Lowercased type, e.g. "feat" or "fix"
+	Scope       string `json:"scope,omitempty"` // Scope in parentheses, if any
+	Breaking    bool   `json:"breaking"`        // Whether the subject has a "!" or the body has a BREAKING CHANGE footer
+	Description string `json:"description"`     // Subject after the type and scope
+}
+
+// ParseConventionalCommit parses a commit subject and body according to Conventional Commits.
+// If the subject does not follow Conventional Commits then nil is returned.
+func ParseConventionalCommit(subject, body string) *ConventionalCommit {
+	match := conventionalSubject.FindStringSubmatch(strings.TrimSpace(subject))
+	if match == nil {
+		return nil
+	}
+
+	commit := &ConventionalCommit{
+		Type:        strings.ToLower(match[1]),
+		Scope:       strings.TrimSpace(match[2]),
+		Breaking:    len(match[3]) > 0,
+		Description: strings.TrimSpace(match[4]),
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		if breakingFooter.MatchString(scanner.Text()) {
+			commit.Breaking = true
+		}
+	}
+
+	return commit
+}
+
+// Conventional returns the commit's message parsed according to Conventional Commits,
+// or nil if it does not follow them.
+func (c GitCommit) Conventional() *ConventionalCommit {
+	return ParseConventionalCommit(c.Description, c.Body)
+}
@@ -0,0 +1,41 @@
+package lib
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseConventionalCommit(t *testing.T) {
+	testCases := []struct {
+		subject  string
+		body     string
+		expected *ConventionalCommit
+	}{
+		{subject: "feat: add arrays", expected: &ConventionalCommit{Type: "feat", Description: "add arrays"}},
+		{subject: "Fix(parser): handle EOF", expected: &ConventionalCommit{Type: "fix", Scope: "parser", Description: "handle EOF"}},
+		{subject: "refactor(api)!: rename Do", expected: &ConventionalCommit{Type: "refactor", Scope: "api", Breaking: true, Description: "rename Do"}},
+		{
+			subject:  "feat: drop v1",
+			body:     "Old clients are gone.\n\nBREAKING CHANGE: v1 endpoints were removed",
+			expected: &ConventionalCommit{Type: "feat", Breaking: true, Description: "drop v1"},
+		},
+		{
+			subject:  "fix: typo",
+			body:     "BREAKING-CHANGE: not really",
+			expected: &ConventionalCommit{Type: "fix", Breaking: true, Description: "typo"},
+		},
+		{subject: "fix: typo", body: "Mentions a BREAKING CHANGE: inline", expected: &ConventionalCommit{Type: "fix", Description: "typo"}},
+		{subject: "Update README", expected: nil},
+		{subject: "feat:missing space", expected: nil},
+		{subject: "Merge branch 'main'", expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("subject=%s", tc.subject), func(t *testing.T) {
+			if actual := ParseConventionalCommit(tc.subject, tc.body); !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("Expected %+v but got %+v", tc.expected, actual)
+			}
+		})
+	}
+}
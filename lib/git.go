@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -11,6 +13,7 @@ import (
 type GitCommit struct {
 	SHA         string // Full SHA of the commit
 	Description string // Commit description
+	Body        string // Commit message after the description
 }
 
 // Git represents a Git local repository.
@@ -59,28 +62,206 @@ func (g *Git) DiffFiles(fromSHA, toSHA string) ([]string, error) {
 // Commits are ordered from newest to older.
 func (g *Git) Commits(fromSHA, toSHA string) ([]GitCommit, error) {
 	// TODO: Is no-merges as default weird?
-	out, err := g.runGitCommand("log", `--pretty=format:%H;%s`, "--no-merges", fmt.Sprintf("%s..%s", fromSHA, toSHA))
+	// Commits are terminated by a record separator and fields by a NUL, since bodies span lines.
+	out, err := g.runGitCommand("log", `--pretty=format:%H%x00%s%x00%b%x1e`, "--no-merges", fmt.Sprintf("%s..%s", fromSHA, toSHA))
 	if err != nil {
 		return nil, err
 	}
 
 	var commits []GitCommit
+	for _, record := range strings.Split(string(out), "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		if len(record) == 0 {
+			continue
+		}
+		parts := strings.SplitN(record, "\x00", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("Unexpected git log output: %q", record)
+		}
+		commits = append(commits, GitCommit{SHA: parts[0], Description: parts[1], Body: strings.TrimSpace(parts[2])})
+	}
+
+	return commits, nil
+}
+
+// CommitFiles returns the list of files changed in the commit of the given SHA.
+// The file names are relative to the root of the Go repository.
+func (g *Git) CommitFiles(sha string) ([]string, error) {
+	out, err := g.runGitCommand("diff-tree", "--no-commit-id", "--name-only", "-r", sha)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
 	scanner := bufio.NewScanner(bytes.NewReader(out))
 	for scanner.Scan() {
-		parts := strings.SplitN(scanner.Text(), ";", 2)
-		commits = append(commits, GitCommit{SHA: parts[0], Description: parts[1]})
+		files = append(files, scanner.Text())
 	}
 	if err := scanner.Err(); err != nil {
 		return nil, err
 	}
 
-	return commits, nil
+	return files, nil
 }
 
-// CommitFiles returns the list of files changed in the commit of the given SHA.
+// ListFiles returns the files directly within a directory as of the commit of the given SHA.
+// The directory and returned file names are relative to the root of the Go repository.
+func (g *Git) ListFiles(sha, dir string) ([]string, error) {
+	args := []string{"ls-tree", "--name-only", sha}
+	if len(dir) > 0 && dir != "." {
+		args = append(args, "--", fmt.Sprintf("%s/", dir))
+	}
+	out, err := g.runGitCommand(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		files = append(files, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// CommitPatch returns the patch of the commit of the given SHA, limited to the given files.
 // The file names are relative to the root of the Go repository.
-func (g *Git) CommitFiles(sha string) ([]string, error) {
-	out, err := g.runGitCommand("diff-tree", "--no-commit-id", "--name-only", "-r", sha)
+func (g *Git) CommitPatch(sha string, files ...string) (string, error) {
+	args := append([]string{"show", "--format=", sha, "--"}, files...)
+	out, err := g.runGitCommand(args...)
+	if err != nil {
+		return "", err
+	}
+
+	return string(out), nil
+}
+
+// DiffChurn returns the number of lines added and deleted in each of the given files after fromSHA
+// through toSHA. E.g. (fromSha, toSHA]. Binary files have no churn, and neither does an empty list of files.
+// The file names are relative to the root of the Go repository.
+func (g *Git) DiffChurn(fromSHA, toSHA string, files ...string) (map[string]int, error) {
+	if len(files) == 0 {
+		return make(map[string]int), nil
+	}
+	args := append([]string{"diff", "--numstat", fmt.Sprintf("%s..%s", fromSHA, toSHA), "--"}, files...)
+	return g.churn(args...)
+}
+
+// CommitChurn returns the number of lines added and deleted in each of the given files by the commit
+// of the given SHA. Binary files have no churn, and neither does an empty list of files.
+// The file names are relative to the root of the Go repository.
+func (g *Git) CommitChurn(sha string, files ...string) (map[string]int, error) {
+	if len(files) == 0 {
+		return make(map[string]int), nil
+	}
+	args := append([]string{"show", "--numstat", "--format=", sha, "--"}, files...)
+	return g.churn(args...)
+}
+
+func (g *Git) churn(args ...string) (map[string]int, error) {
+	out, err := g.runGitCommand(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	churn := make(map[string]int)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		// Lines are "added<TAB>deleted<TAB>file", with "-" counts for binary files.
+		parts := strings.SplitN(scanner.Text(), "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		added, _ := strconv.Atoi(parts[0])
+		deleted, _ := strconv.Atoi(parts[1])
+		churn[parts[2]] += added + deleted
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return churn, nil
+}
+
+// GitAuthorLines is the number of lines in a file last changed by an author.
+type GitAuthorLines struct {
+	Name  string
+	Email string
+	Lines int
+}
+
+// BlameRange returns the authors of the lines of a file as of toSHA that were last changed after
+// fromSHA through toSHA. E.g. (fromSha, toSHA]. Authors are ordered by number of lines, descending,
+// and then by email. If the file does not exist as of toSHA then nil is returned.
+// The file name is relative to the root of the Go repository.
+func (g *Git) BlameRange(fromSHA, toSHA, file string) ([]GitAuthorLines, error) {
+	if exists, err := g.fileExists(toSHA, file); err != nil || !exists {
+		return nil, err
+	}
+
+	out, err := g.runGitCommand("blame", "--line-porcelain", fmt.Sprintf("%s..%s", fromSHA, toSHA), "--", file)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseBlame(out)
+}
+
+// parseBlame parses the output of git blame --line-porcelain into the authors of lines that are not
+// boundary lines, ordered by number of lines, descending, and then by email.
+func parseBlame(out []byte) ([]GitAuthorLines, error) {
+	// Each line is described by a header of "key value" lines and ends with its tab-prefixed content.
+	// Lines last changed at or before the start of the range are marked as "boundary".
+	authors := make(map[string]*GitAuthorLines)
+	var name, email string
+	boundary := false
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			if !boundary {
+				if _, ok := authors[email]; !ok {
+					authors[email] = &GitAuthorLines{Name: name, Email: email}
+				}
+				authors[email].Lines++
+			}
+			boundary = false
+		case strings.HasPrefix(line, "author "):
+			name = strings.TrimPrefix(line, "author ")
+		case strings.HasPrefix(line, "author-mail "):
+			email = strings.Trim(strings.TrimPrefix(line, "author-mail "), "<>")
+		case line == "boundary":
+			boundary = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	lines := make([]GitAuthorLines, 0, len(authors))
+	for _, author := range authors {
+		lines = append(lines, *author)
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Lines != lines[j].Lines {
+			return lines[i].Lines > lines[j].Lines
+		}
+		return lines[i].Email < lines[j].Email
+	})
+
+	return lines, nil
+}
+
+// TrackedFiles returns all files tracked in the Git repository's index.
+// The file names are relative to the root of the Go repository.
+func (g *Git) TrackedFiles() ([]string, error) {
+	out, err := g.runGitCommand("ls-files")
 	if err != nil {
 		return nil, err
 	}
@@ -97,6 +278,28 @@ func (g *Git) CommitFiles(sha string) ([]string, error) {
 	return files, nil
 }
 
+// FileAt returns the contents of a file as of the commit of the given SHA.
+// The file name is relative to the root of the Go repository.
+// If the file does not exist at that commit then nil is returned.
+func (g *Git) FileAt(sha, file string) ([]byte, error) {
+	if exists, err := g.fileExists(sha, file); err != nil || !exists {
+		return nil, err
+	}
+
+	return g.runGitCommand("show", fmt.Sprintf("%s:%s", sha, file))
+}
+
+// fileExists returns true if a file exists as of the commit of the given SHA.
+// The file name is relative to the root of the Go repository.
+func (g *Git) fileExists(sha, file string) (bool, error) {
+	out, err := g.runGitCommand("ls-tree", "--name-only", sha, "--", file)
+	if err != nil {
+		return false, err
+	}
+
+	return len(strings.TrimSpace(string(out))) > 0, nil
+}
+
 func (g *Git) runGitCommand(args ...string) ([]byte, error) {
 	args = append([]string{"-C", g.RootDir}, args...)
 	return RunCommand("git", args...)
@@ -0,0 +1,212 @@
+package lib
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseBlame(t *testing.T) {
+	out := `1111111111111111111111111111111111111111 1 1 2
+author Alice
+author-mail <alice@example.com>
+author-time 1700000000
+author-tz +0000
+committer Alice
+committer-mail <alice@example.com>
+committer-time 1700000000
+committer-tz +0000
+summary feat: add lines
+filename lib/lib.go
+	package lib
+1111111111111111111111111111111111111111 2 2
+author Alice
+author-mail <alice@example.com>
+author-time 1700000000
+author-tz +0000
+committer Alice
+committer-mail <alice@example.com>
+committer-time 1700000000
+committer-tz +0000
+summary feat: add lines
+filename lib/lib.go
+	author Mallory
+2222222222222222222222222222222222222222 3 3 1
+author Bob Builder
+author-mail <bob@example.com>
+author-time 1600000000
+author-tz +0000
+committer Bob Builder
+committer-mail <bob@example.com>
+committer-time 1600000000
+committer-tz +0000
+summary initial commit
+boundary
+filename lib/lib.go
+	func old() {}
+3333333333333333333333333333333333333333 4 4 1
+author Carol
+author-mail <carol@example.com>
+author-time 1700000001
+author-tz +0000
+committer Carol
+committer-mail <carol@example.com>
+committer-time 1700000001
+committer-tz +0000
+summary fix: tweak
+filename lib/lib.go
+	func new() {}
+4444444444444444444444444444444444444444 5 5 1
+author Alice
+author-mail <alice@example.com>
+author-time 1700000002
+author-tz +0000
+committer Alice
+committer-mail <alice@example.com>
+committer-time 1700000002
+committer-tz +0000
+summary fix: more
+filename lib/lib.go
+	
+`
+
+	expected := []GitAuthorLines{
+		{Name: "Alice", Email: "alice@example.com", Lines: 3},
+		{Name: "Carol", Email: "carol@example.com", Lines: 1},
+	}
+	actual, err := parseBlame([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %+v but got %+v", expected, actual)
+	}
+
+	if actual, err := parseBlame(nil); err != nil || len(actual) != 0 {
+		t.Fatalf("Expected no authors but got %+v, %v", actual, err)
+	}
+}
+
+// newTestGit returns a Git repository in a temp directory with a commit of each set of files, and a function
+// that removes it. The test is skipped if git is not installed.
+func newTestGit(t *testing.T, commits ...map[string]string) (*Git, func()) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "tdiff-git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	git := &Git{RootDir: dir}
+	if _, err := git.runGitCommand("init", "-q"); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	for i, files := range commits {
+		for file, body := range files {
+			if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(file)), 0755); err != nil {
+				os.RemoveAll(dir)
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(dir, file), []byte(body), 0644); err != nil {
+				os.RemoveAll(dir)
+				t.Fatal(err)
+			}
+		}
+		for _, args := range [][]string{
+			{"add", "-A"},
+			{"-c", "user.name=Alice", "-c", "user.email=alice@example.com", "commit", "-q", "-m", fmt.Sprintf("commit %d", i)},
+		} {
+			if _, err := git.runGitCommand(args...); err != nil {
+				os.RemoveAll(dir)
+				t.Fatal(err)
+			}
+		}
+	}
+
+	return git, func() { os.RemoveAll(dir) }
+}
+
+func TestFileExists(t *testing.T) {
+	git, cleanup := newTestGit(t, map[string]string{"lib/lib.go": "package lib\n"})
+	defer cleanup()
+
+	testCases := []struct {
+		file   string
+		exists bool
+	}{
+		{file: "lib/lib.go", exists: true},
+		{file: "lib/missing.go", exists: false},
+		{file: "lib/lib", exists: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.file, func(t *testing.T) {
+			exists, err := git.fileExists("HEAD", tc.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exists != tc.exists {
+				t.Fatalf("Expected %v but got %v", tc.exists, exists)
+			}
+
+			body, err := git.FileAt("HEAD", tc.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (body != nil) != tc.exists {
+				t.Fatalf("Expected contents only for existing files but got %q", body)
+			}
+
+			authors, err := git.BlameRange("HEAD", "HEAD", tc.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tc.exists && authors != nil {
+				t.Fatalf("Expected no authors for a missing file but got %+v", authors)
+			}
+		})
+	}
+}
+
+func TestChurn(t *testing.T) {
+	git, cleanup := newTestGit(t,
+		map[string]string{"lib/lib.go": "package lib\n", "other.txt": "a\n"},
+		map[string]string{"lib/lib.go": "package lib\n\nfunc F() {}\n", "other.txt": "b\nc\n"},
+	)
+	defer cleanup()
+
+	testCases := []struct {
+		files    []string
+		expected map[string]int
+	}{
+		{files: nil, expected: map[string]int{}},
+		{files: []string{"lib/lib.go"}, expected: map[string]int{"lib/lib.go": 2}},
+		{files: []string{"lib/lib.go", "other.txt"}, expected: map[string]int{"lib/lib.go": 2, "other.txt": 3}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("files=%v", tc.files), func(t *testing.T) {
+			churn, err := git.DiffChurn("HEAD^", "HEAD", tc.files...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tc.expected, churn) {
+				t.Fatalf("Expected diff churn %v but got %v", tc.expected, churn)
+			}
+
+			if churn, err = git.CommitChurn("HEAD", tc.files...); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tc.expected, churn) {
+				t.Fatalf("Expected commit churn %v but got %v", tc.expected, churn)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package lib
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchGlob returns true iff the slash separated name matches the glob pattern.
+// Patterns use path.Match syntax for each path element, and a "**" element matches
+// zero or more path elements. A pattern that matches a directory also matches
+// everything within it.
+// MatchGlob("a/*.txt", "a/b.txt") == true
+// MatchGlob("a/**/c.txt", "a/b/b/c.txt") == true
+// MatchGlob("a/b", "a/b/c.txt") == true
+func MatchGlob(pattern, name string) bool {
+	return matchGlobParts(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(name, "/"), true)
+}
+
+// matchGlobParts matches a split name against a split glob pattern. If within is true, a pattern that
+// matches a directory also matches everything within it.
+func matchGlobParts(patternParts, nameParts []string, within bool) bool {
+	if len(patternParts) == 0 {
+		// The pattern matched a directory containing the rest of the name.
+		return within || len(nameParts) == 0
+	}
+
+	if patternParts[0] == "**" {
+		for i := 0; i <= len(nameParts); i++ {
+			if matchGlobParts(patternParts[1:], nameParts[i:], within) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(nameParts) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(patternParts[0], nameParts[0]); !matched {
+		return false
+	}
+
+	return matchGlobParts(patternParts[1:], nameParts[1:], within)
+}
@@ -0,0 +1,35 @@
+package lib
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		name    string
+		matches bool
+	}{
+		{pattern: "Dockerfile", name: "Dockerfile", matches: true},
+		{pattern: "Dockerfile", name: "svc/Dockerfile", matches: false},
+		{pattern: "configs/*.yaml", name: "configs/svc.yaml", matches: true},
+		{pattern: "configs/*.yaml", name: "configs/svc/svc.yaml", matches: false},
+		{pattern: "configs", name: "configs/svc/svc.yaml", matches: true},
+		{pattern: "configs/", name: "configs/svc/svc.yaml", matches: true},
+		{pattern: "**/*.sql", name: "migrations/001.sql", matches: true},
+		{pattern: "**/*.sql", name: "001.sql", matches: true},
+		{pattern: "proto/**/svc.proto", name: "proto/a/b/svc.proto", matches: true},
+		{pattern: "proto/**/svc.proto", name: "proto/svc.proto", matches: true},
+		{pattern: "proto/**/svc.proto", name: "proto/a/other.proto", matches: false},
+		{pattern: "proto/**", name: "proto/a/other.proto", matches: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("pattern=%s name=%s", tc.pattern, tc.name), func(t *testing.T) {
+			if actual := MatchGlob(tc.pattern, tc.name); actual != tc.matches {
+				t.Errorf("Expected %v but got %v", tc.matches, actual)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package lib
 
 import (
+	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -12,7 +13,7 @@ func RunCommand(cmd string, args ...string) ([]byte, error) {
 	out, err := exec.Command(cmd, args...).Output()
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
-			err = fmt.Errorf(string(exitErr.Stderr))
+			err = errors.New(string(exitErr.Stderr))
 		}
 
 		return nil, fmt.Errorf("Error running command `%s %s`: %v", cmd, strings.Join(args, " "), err)
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/alecholmes/tdiff/app"
 	"github.com/alecholmes/tdiff/importer"
@@ -19,24 +20,76 @@ var (
 
 	// Optional flags
 	artifactsFlag = flag.Bool("artifacts", false, "If true, includes changed non-Go source files under the package directory, recursive")
+	symbolsFlag   = flag.Bool("symbols", false, "If set, Go changes are only relevant if they touch declarations used by the package, recursive")
+	classifyFlag  = flag.Bool("classify", false, "If set, changes are classified as formatting, comment, test or code changes")
+	codeOnlyFlag  = flag.Bool("code-only", false, "If set, only code changes are relevant; formatting, comment and test changes are ignored")
+	apiGateFlag   = flag.Bool("api-gate", false, "If set, exit with status 2 when a relevant package has breaking exported API changes")
+	impactFlag    = flag.Bool("impact", false, "If set, the depth, fan-in and reach of each relevant changed package in the package's graph are computed")
+	sortFlag      = flag.String("sort", app.SortByName, "Order of relevant changed packages: name, depth, fan-in or reach; orderings other than name imply -impact")
+	riskGateFlag  = flag.Float64("risk-threshold", 0, "If positive, exit with status 3 when the risk score of relevant changes is above this; overrides the config threshold")
+	generateFlag  = flag.Bool("generate", false, "If set, //go:generate generators and their inputs are treated as dependencies")
+	configFlag    = flag.String("config", "", "Config file to use; defaults to "+app.DefaultConfigFile+" at the root of the Git repository, if it exists")
 	verboseFlag   = flag.Bool("verbose", false, "If set, log verbose debugging information")
 
 	// Output format flags
-	packagesFlag = flag.Bool("packages", false, "If set, all relevant changed packages printed")
-	filesFlag    = flag.Bool("files", false, "If set, all relevant changed files are printed")
-	commitsFlag  = flag.Bool("commits", false, "If set, all relevant commits are printed")
-	jsonFlag     = flag.Bool("json", false, "If set, JSON object representing all changes is printed")
-	htmlFlag     = flag.Bool("html", false, "If set, an HTML summary is written to a temp file")
+	packagesFlag  = flag.Bool("packages", false, "If set, all relevant changed packages printed")
+	filesFlag     = flag.Bool("files", false, "If set, all relevant changed files are printed")
+	commitsFlag   = flag.Bool("commits", false, "If set, all relevant commits are printed")
+	apiFlag       = flag.Bool("api", false, "If set, exported API changes of relevant packages are printed")
+	riskFlag      = flag.Bool("risk", false, "If set, the risk scores of relevant changes and of each relevant commit are printed")
+	pathsFlag     = flag.Bool("paths", false, "If set, the import paths from the package to each relevant changed package, and its direct importers, are printed")
+	jsonFlag      = flag.Bool("json", false, "If set, JSON object representing all changes is printed")
+	ndjsonFlag    = flag.Bool("ndjson", false, "If set, the summary is printed as newline-delimited JSON events of packages, files, commits and aggregates as soon as they are determined")
+	dotFlag       = flag.Bool("dot", false, "If set, the package graph is printed in the Graphviz DOT language, highlighting changed packages")
+	mermaidFlag   = flag.Bool("mermaid", false, "If set, the package graph is printed as a Mermaid flowchart")
+	graphMLFlag   = flag.Bool("graphml", false, "If set, the package graph is printed as GraphML")
+	jsonGraphFlag = flag.Bool("json-graph", false, "If set, the package graph is printed in the JSON Graph Format")
+	ownersFlag    = flag.Bool("owners", false, "If set, the owners of relevant changed files in CODEOWNERS are printed with their changed packages and files")
+	blameFlag     = flag.Bool("blame", false, "If set, the authors and teams of the lines of relevant files changed in range are printed")
+	markdownFlag  = flag.Bool("markdown", false, "If set, release notes of relevant commits are printed as markdown")
+	changelogFlag = flag.Bool("changelog", false, "If set, a markdown changelog entry of relevant commits grouped by conventional commit type is printed")
+	bumpFlag      = flag.Bool("bump", false, "If set, the semantic version bump suggested by relevant conventional commits is printed, and the next version if -version is set")
+	htmlFlag      = flag.Bool("html", false, "If set, an HTML summary is written to a temp file")
+
+	// Findings output flags
+	sarifFlag             = flag.Bool("sarif", false, "If set, findings about relevant changes (changed files, new imports, breaking API changes, policy violations) are printed as SARIF")
+	githubAnnotationsFlag = flag.Bool("github-annotations", false, "If set, findings about relevant changes are printed as GitHub Actions workflow commands")
+	gitlabCodeQualityFlag = flag.Bool("gitlab-codequality", false, "If set, findings about relevant changes are printed as a GitLab code quality report")
+
+	// Graph output flags
+	graphPathsFlag    = flag.Bool("graph-paths-only", false, "If set, graphs only include packages on the shortest paths to changed packages")
+	graphCollapseFlag = flag.String("graph-collapse", "", "Comma separated import path prefixes; graphs collapse the packages under each into one node")
+	dotPathsFlag      = flag.Bool("dot-paths-only", false, "Deprecated alias of -graph-paths-only")
+
+	// Release note flags
+	markdownGroupFlag = flag.String("markdown-group", app.GroupByType, "How release notes group commits: type (conventional commit type) or package")
+	markdownOtherFlag = flag.Bool("markdown-other", false, "If set, release notes include a collapsed list of commits in range that are not relevant")
+	versionFlag       = flag.String("version", "", "Current version of the package, e.g. v1.2.3, used to suggest the next version")
+	commitURLFlag     = flag.String("commit-url", "", "Template of commit links in release notes, where {sha} is the commit SHA; overrides the config")
+
+	// Path output flags
+	maxPathsFlag      = flag.Int("max-paths", 10, "Maximum number of import paths enumerated per package with -paths, or 0 for no limit; shortest paths come first")
+	maxPathLengthFlag = flag.Int("max-path-length", 0, "If positive, the maximum number of packages in import paths enumerated with -paths")
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "why" {
+		why(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		check(os.Args[2:])
+		return
+	}
+
 	flag.Parse()
 	if len(*packageFlag) == 0 || len(*shaFlag) == 0 {
 		flag.Usage()
 		os.Exit(1)
 	}
 
-	includePaths := *jsonFlag || *htmlFlag
+	includePaths := *jsonFlag || *ndjsonFlag || *htmlFlag
+	findings := *sarifFlag || *githubAnnotationsFlag || *gitlabCodeQualityFlag
 	logger := app.NoLogging
 	if *verboseFlag {
 		logger = log.Printf
@@ -44,14 +97,65 @@ func main() {
 
 	differ := app.NewDiffer(os.Getenv("GOPATH"), importer.DefaultRecursiveImport, *commitsFlag, includePaths, logger)
 
-	summary, err := differ.Diff(*packageFlag, *shaFlag, *artifactsFlag)
+	opts := app.Options{
+		Artifacts: *artifactsFlag,
+		Symbols:   *symbolsFlag,
+		API:       *apiFlag || *apiGateFlag || findings,
+		Classify:  *classifyFlag,
+		CodeOnly:  *codeOnlyFlag,
+		Generate:  *generateFlag,
+		Patches:   *htmlFlag,
+		Other:     *markdownFlag && *markdownOtherFlag,
+		Owners:    *ownersFlag,
+		Blame:     *blameFlag,
+		Policies:  findings,
+		Findings:  findings,
+		Impact:    *impactFlag,
+		SortBy:    *sortFlag,
+
+		Risk:          *riskFlag,
+		RiskThreshold: *riskGateFlag,
+
+		Paths:         *pathsFlag,
+		MaxPaths:      *maxPathsFlag,
+		MaxPathLength: *maxPathLengthFlag,
+
+		ConfigFile: *configFlag,
+	}
+
+	// Streamed commits are not retained in the summary, so they are collected if other outputs print them.
+	collectCommits := *commitsFlag || *riskFlag || *jsonFlag || *markdownFlag || *changelogFlag || *htmlFlag
+	commits := []*app.Commit{}
+	var emit func(*app.Event) error
+	if *ndjsonFlag {
+		emit = func(event *app.Event) error {
+			if event.Type == app.EventCommit && collectCommits {
+				commits = append(commits, event.Commit)
+			}
+			body, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Println(string(body))
+			return err
+		}
+	}
+
+	summary, err := differ.DiffEvents(*packageFlag, *shaFlag, opts, emit)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if emit != nil {
+		summary.Commits = commits
+	}
 
 	if *packagesFlag {
 		for _, pkg := range summary.Packages {
-			fmt.Println(pkg.ImportPath)
+			if pkg.Impact != nil {
+				fmt.Printf("%s depth=%d fan-in=%d reach=%.0f%%\n", pkg.ImportPath, pkg.Impact.Depth, pkg.Impact.FanIn, pkg.Impact.Reach*100)
+			} else {
+				fmt.Println(pkg.ImportPath)
+			}
 		}
 	}
 
@@ -67,6 +171,67 @@ func main() {
 		}
 	}
 
+	if *apiFlag {
+		for _, pkg := range summary.Packages {
+			for _, change := range pkg.APIChanges {
+				compatibility := "compatible"
+				if change.Breaking {
+					compatibility = "breaking"
+				}
+				fmt.Printf("%s %s %s %s.%s\n", compatibility, change.Change, change.Kind, pkg.ImportPath, change.Name)
+			}
+		}
+	}
+
+	if *ownersFlag {
+		for _, owner := range summary.Owners {
+			fmt.Println(owner.Name)
+			for _, pkg := range owner.Packages {
+				fmt.Printf("  %s\n", pkg)
+			}
+			for _, file := range owner.Files {
+				fmt.Printf("  %s\n", file)
+			}
+		}
+	}
+
+	if *blameFlag {
+		for _, contributor := range summary.Contributors {
+			fmt.Printf("%d %s <%s> %s\n", contributor.Lines, contributor.Name, contributor.Email, contributor.Team)
+		}
+		for _, team := range summary.Teams {
+			fmt.Printf("%d %s\n", team.Lines, team.Name)
+		}
+	}
+
+	if *riskFlag {
+		risk := summary.Risk
+		if risk.Threshold > 0 {
+			exceeded := "within"
+			if risk.Exceeded {
+				exceeded = "exceeds"
+			}
+			fmt.Printf("risk %.2f (%s threshold %.2f)\n", risk.Score, exceeded, risk.Threshold)
+		} else {
+			fmt.Printf("risk %.2f\n", risk.Score)
+		}
+		for _, commit := range summary.Commits {
+			fmt.Printf("%s %.2f %s\n", commit.SHA, commit.Risk.Score, commit.Description)
+		}
+	}
+
+	if *pathsFlag {
+		for _, pkg := range summary.Packages {
+			fmt.Println(pkg.ImportPath)
+			for _, path := range pkg.Paths {
+				fmt.Printf("  %s\n", strings.Join(path, " > "))
+			}
+			if len(pkg.Importers) > 0 {
+				fmt.Printf("  imported by: %s\n", strings.Join(pkg.Importers, ", "))
+			}
+		}
+	}
+
 	if *jsonFlag {
 		body, err := json.MarshalIndent(summary, "", "  ")
 		if err != nil {
@@ -75,6 +240,75 @@ func main() {
 		fmt.Println(string(body))
 	}
 
+	if *markdownFlag {
+		body, err := app.Markdown(summary, app.MarkdownOptions{GroupBy: *markdownGroupFlag, CommitURL: commitURL(summary)})
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Print(string(body))
+	}
+
+	if *changelogFlag {
+		body, err := app.Changelog(summary, *versionFlag, commitURL(summary))
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Print(string(body))
+	}
+
+	if *bumpFlag {
+		if len(*versionFlag) > 0 {
+			next, err := app.NextVersion(*versionFlag, summary.Bump)
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("%s %s\n", summary.Bump, next)
+		} else {
+			fmt.Println(summary.Bump)
+		}
+	}
+
+	graphOpts := app.GraphOptions{PathsOnly: *graphPathsFlag || *dotPathsFlag}
+	if len(*graphCollapseFlag) > 0 {
+		graphOpts.Collapse = strings.Split(*graphCollapseFlag, ",")
+	}
+	for _, graph := range []struct {
+		enabled bool
+		render  func(*app.Summary, app.GraphOptions) ([]byte, error)
+	}{
+		{*dotFlag, app.DOT},
+		{*mermaidFlag, app.Mermaid},
+		{*graphMLFlag, app.GraphML},
+		{*jsonGraphFlag, app.JSONGraph},
+	} {
+		if !graph.enabled {
+			continue
+		}
+		body, err := graph.render(summary, graphOpts)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Print(string(body))
+	}
+
+	for _, output := range []struct {
+		enabled bool
+		render  func(*app.Summary) ([]byte, error)
+	}{
+		{*sarifFlag, app.SARIF},
+		{*githubAnnotationsFlag, app.GitHubAnnotations},
+		{*gitlabCodeQualityFlag, app.GitLabCodeQuality},
+	} {
+		if !output.enabled {
+			continue
+		}
+		body, err := output.render(summary)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Print(string(body))
+	}
+
 	if *htmlFlag {
 		fileName, err := writeHTML(summary)
 		if err != nil {
@@ -82,6 +316,29 @@ func main() {
 		}
 		fmt.Println(fileName)
 	}
+
+	if summary.Risk != nil && summary.Risk.Exceeded {
+		os.Exit(3)
+	}
+
+	if *apiGateFlag {
+		for _, pkg := range summary.Packages {
+			for _, change := range pkg.APIChanges {
+				if change.Breaking {
+					os.Exit(2)
+				}
+			}
+		}
+	}
+}
+
+// commitURL returns the template of commit links, from the flag or the config.
+func commitURL(summary *app.Summary) string {
+	if len(*commitURLFlag) > 0 {
+		return *commitURLFlag
+	}
+
+	return summary.Config.Links.Commit
 }
 
 func writeHTML(summary *app.Summary) (string, error) {
@@ -0,0 +1,294 @@
+package source
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// API kinds of exported declarations.
+const (
+	APIFunc            = "func"
+	APIMethod          = "method"
+	APIType            = "type"
+	APIField           = "field"
+	APIInterfaceMethod = "interface method"
+	APIConst           = "const"
+	APIVar             = "var"
+)
+
+// API change kinds.
+const (
+	APIAdded   = "added"
+	APIRemoved = "removed"
+	APIChanged = "changed"
+)
+
+// APIDecl describes a single element of a package's exported API.
+type APIDecl struct {
+	Kind      string // One of the API kind constants, e.g. APIFunc
+	Signature string // Declaration without implementation, e.g. "func F(int) error"
+}
+
+// API is the exported API of a package, keyed by name.
+// Methods, struct fields and interface methods are keyed as "Type.Name".
+type API map[string]APIDecl
+
+// APIChange describes an addition, removal or change to an element of a package's exported API.
+type APIChange struct {
+	Name         string `json:"name"`
+	Kind         string `json:"kind"`   // One of the API kind constants
+	Change       string `json:"change"` // One of APIAdded, APIRemoved or APIChanged
+	Breaking     bool   `json:"breaking"`
+	OldSignature string `json:"oldSignature,omitempty"`
+	NewSignature string `json:"newSignature,omitempty"`
+}
+
+// ExtractAPI returns the exported API declared in the given files.
+// Only members of exported types are included. Parameter and receiver names are not
+// part of the API, so renaming them is not a change. The values of constants, and of
+// variables without a declared type, are part of the API since they determine the
+// constant's value or the variable's type.
+func ExtractAPI(fset *token.FileSet, files []*ast.File) (API, error) {
+	api := make(API)
+	add := func(name, kind, prefix string, node interface{}) error {
+		text, err := nodeText(fset, node)
+		if err != nil {
+			return err
+		}
+		api[name] = APIDecl{Kind: kind, Signature: prefix + text}
+		return nil
+	}
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if !d.Name.IsExported() {
+					continue
+				}
+				signature := &ast.FuncDecl{Recv: unnamedFields(d.Recv), Name: d.Name, Type: unnamedFuncType(d.Type)}
+				if d.Recv == nil {
+					if err := add(d.Name.Name, APIFunc, "", signature); err != nil {
+						return nil, err
+					}
+				} else if recv := ReceiverTypeName(d); ast.IsExported(recv) {
+					if err := add(recv+"."+d.Name.Name, APIMethod, "", signature); err != nil {
+						return nil, err
+					}
+				}
+			case *ast.GenDecl:
+				// Constants without values repeat the type and values of the previous constant in the group.
+				var constType ast.Expr
+				var constValues []ast.Expr
+				for i, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						if !s.Name.IsExported() {
+							continue
+						}
+						if err := extractTypeAPI(s, add); err != nil {
+							return nil, err
+						}
+					case *ast.ValueSpec:
+						kind := APIVar
+						valueType, values := s.Type, s.Values
+						if d.Tok == token.CONST {
+							kind = APIConst
+							if len(values) == 0 {
+								valueType, values = constType, constValues
+							}
+							constType, constValues = valueType, values
+						}
+						for j, name := range s.Names {
+							if !name.IsExported() {
+								continue
+							}
+							valueSpec := &ast.ValueSpec{Names: []*ast.Ident{name}, Type: valueType}
+							// The values of constants, and of variables with inferred types, are part of the API.
+							if d.Tok == token.CONST && j < len(values) {
+								value, err := constValue(fset, values[j], i)
+								if err != nil {
+									return nil, err
+								}
+								valueSpec.Values = []ast.Expr{value}
+							} else if d.Tok == token.VAR && valueType == nil && len(values) == len(s.Names) {
+								valueSpec.Values = []ast.Expr{values[j]}
+							} else if d.Tok == token.VAR && valueType == nil {
+								valueSpec.Values = values
+							}
+							signature := &ast.GenDecl{Tok: d.Tok, Specs: []ast.Spec{valueSpec}}
+							if err := add(name.Name, kind, "", signature); err != nil {
+								return nil, err
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return api, nil
+}
+
+// constValue returns a copy of the value expression of a constant in which iota is replaced by the
+// index of the constant's spec, so that reordering constants changes their values.
+func constValue(fset *token.FileSet, value ast.Expr, index int) (ast.Expr, error) {
+	text, err := nodeText(fset, value)
+	if err != nil {
+		return nil, err
+	}
+	expr, err := parser.ParseExpr(text)
+	if err != nil {
+		return nil, err
+	}
+	ast.Inspect(expr, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok && ident.Name == "iota" {
+			ident.Name = strconv.Itoa(index)
+		}
+		return true
+	})
+
+	return expr, nil
+}
+
+// extractTypeAPI adds an exported type to the API. Struct fields and interface methods
+// are added as separate elements so that they can be compared individually.
+func extractTypeAPI(spec *ast.TypeSpec, add func(name, kind, prefix string, node interface{}) error) error {
+	shell := &ast.TypeSpec{Name: spec.Name, TypeParams: spec.TypeParams, Assign: spec.Assign, Type: spec.Type}
+	switch t := spec.Type.(type) {
+	case *ast.StructType:
+		shell.Type = &ast.StructType{Fields: &ast.FieldList{}}
+		for _, field := range t.Fields.List {
+			for _, name := range fieldNames(field) {
+				if ast.IsExported(name) {
+					if err := add(spec.Name.Name+"."+name, APIField, name+" ", field.Type); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	case *ast.InterfaceType:
+		shell.Type = &ast.InterfaceType{Methods: &ast.FieldList{}}
+		for _, method := range t.Methods.List {
+			methodType := method.Type
+			if funcType, ok := methodType.(*ast.FuncType); ok {
+				methodType = unnamedFuncType(funcType)
+			}
+			// Unexported methods and embedded interfaces still change the method set.
+			for _, name := range fieldNames(method) {
+				if err := add(spec.Name.Name+"."+name, APIInterfaceMethod, name+" ", methodType); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return add(spec.Name.Name, APIType, "", &ast.GenDecl{Tok: token.TYPE, Specs: []ast.Spec{shell}})
+}
+
+// fieldNames returns the names of a struct field or interface method.
+// Embedded fields are named by their type.
+func fieldNames(field *ast.Field) []string {
+	if len(field.Names) > 0 {
+		names := make([]string, 0, len(field.Names))
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+		return names
+	}
+
+	expr := field.Type
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.SelectorExpr:
+			return []string{e.Sel.Name}
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return []string{e.Name}
+		default:
+			// Type constraint elements, such as ~int | ~string
+			text, _ := nodeText(token.NewFileSet(), expr)
+			return []string{text}
+		}
+	}
+}
+
+// unnamedFuncType returns a copy of a function type without parameter or result names.
+func unnamedFuncType(funcType *ast.FuncType) *ast.FuncType {
+	return &ast.FuncType{
+		TypeParams: funcType.TypeParams,
+		Params:     unnamedFields(funcType.Params),
+		Results:    unnamedFields(funcType.Results),
+	}
+}
+
+// unnamedFields returns a copy of a field list with one unnamed field per name.
+func unnamedFields(fields *ast.FieldList) *ast.FieldList {
+	if fields == nil {
+		return nil
+	}
+
+	unnamed := &ast.FieldList{}
+	for _, field := range fields.List {
+		for i := 0; i < len(field.Names) || i == 0; i++ {
+			unnamed.List = append(unnamed.List, &ast.Field{Type: field.Type})
+		}
+	}
+
+	return unnamed
+}
+
+// CompareAPI returns the changes between two versions of a package's API, ordered by name.
+// Removed and changed elements are breaking. Added elements are compatible, except for
+// methods added to interfaces since existing implementations no longer satisfy them.
+func CompareAPI(oldAPI, newAPI API) []*APIChange {
+	var changes []*APIChange
+	for name, oldDecl := range oldAPI {
+		newDecl, ok := newAPI[name]
+		if !ok {
+			changes = append(changes, &APIChange{
+				Name:         name,
+				Kind:         oldDecl.Kind,
+				Change:       APIRemoved,
+				Breaking:     true,
+				OldSignature: oldDecl.Signature,
+			})
+		} else if oldDecl != newDecl {
+			changes = append(changes, &APIChange{
+				Name:         name,
+				Kind:         newDecl.Kind,
+				Change:       APIChanged,
+				Breaking:     true,
+				OldSignature: oldDecl.Signature,
+				NewSignature: newDecl.Signature,
+			})
+		}
+	}
+	for name, newDecl := range newAPI {
+		if _, ok := oldAPI[name]; !ok {
+			_, typeExisted := oldAPI[strings.SplitN(name, ".", 2)[0]]
+			changes = append(changes, &APIChange{
+				Name:         name,
+				Kind:         newDecl.Kind,
+				Change:       APIAdded,
+				Breaking:     newDecl.Kind == APIInterfaceMethod && typeExisted,
+				NewSignature: newDecl.Signature,
+			})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
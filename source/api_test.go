@@ -0,0 +1,118 @@
+package source
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func parseTestAPI(t *testing.T, body string) API {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "api.go", body, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api, err := ExtractAPI(fset, []*ast.File{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api
+}
+
+func TestCompareAPI(t *testing.T) {
+	parseAPI := func(body string) API {
+		return parseTestAPI(t, body)
+	}
+
+	oldAPI := parseAPI(`package p
+type Config struct {
+	Name   string
+	secret string
+}
+type Runner interface {
+	Run(ctx string) error
+}
+func (c *Config) Validate(strict bool) error { return nil }
+func Removed() {}
+func Renamed(a, b int) {}
+func helper() {}
+`)
+	newAPI := parseAPI(`package p
+type Config struct {
+	Name    string
+	Timeout int
+}
+type Runner interface {
+	Run(context string) error
+	Stop()
+}
+func (cfg *Config) Validate(strict bool) error { return nil }
+func Renamed(x, y int) {}
+func Added() {}
+func helper() int { return 0 }
+`)
+
+	type change struct {
+		name     string
+		change   string
+		breaking bool
+	}
+	var actual []change
+	for _, c := range CompareAPI(oldAPI, newAPI) {
+		actual = append(actual, change{name: c.Name, change: c.Change, breaking: c.Breaking})
+	}
+
+	expected := []change{
+		{name: "Added", change: APIAdded, breaking: false},
+		{name: "Config.Timeout", change: APIAdded, breaking: false},
+		{name: "Removed", change: APIRemoved, breaking: true},
+		{name: "Runner.Stop", change: APIAdded, breaking: true},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected changes %v but got %v", expected, actual)
+	}
+}
+
+func TestValueAPI(t *testing.T) {
+	testCases := []struct {
+		name     string
+		old      string
+		new      string
+		expected []string
+	}{
+		{name: "const value", old: "const Timeout = 5", new: `const Timeout = "5s"`, expected: []string{"Timeout changed breaking=true"}},
+		{name: "same const value", old: "const Timeout = 5", new: "const Timeout = 5"},
+		{name: "typed const value", old: "const Timeout int = 5", new: "const Timeout int = 6", expected: []string{"Timeout changed breaking=true"}},
+		{name: "const moved", old: "const (\n\tA = iota\n\tB\n)", new: "const (\n\tB = iota\n\tA\n)", expected: []string{"A changed breaking=true", "B changed breaking=true"}},
+		{name: "const appended", old: "const (\n\tA = iota\n\tB\n)", new: "const (\n\tA = iota\n\tB\n\tC\n)", expected: []string{"C added breaking=false"}},
+		{name: "implicit const value", old: "const (\n\tA = 1\n\tB\n)", new: "const (\n\tA = 2\n\tB\n)", expected: []string{"A changed breaking=true", "B changed breaking=true"}},
+		{name: "unexported const", old: "const timeout = 5", new: `const timeout = "5s"`},
+		{name: "inferred var type", old: "var X = 1", new: `var X = "a"`, expected: []string{"X changed breaking=true"}},
+		{name: "inferred var from call", old: "var X, Y = f()", new: "var X, Y = g()", expected: []string{"X changed breaking=true", "Y changed breaking=true"}},
+		{name: "typed var value", old: "var X int = 1", new: "var X int = 2"},
+		{name: "typed var type", old: "var X int = 1", new: "var X int64 = 1", expected: []string{"X changed breaking=true"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("case=%s", tc.name), func(t *testing.T) {
+			oldAPI := parseTestAPI(t, "package p\n"+tc.old+"\n")
+			newAPI := parseTestAPI(t, "package p\n"+tc.new+"\n")
+
+			var actual []string
+			for _, change := range CompareAPI(oldAPI, newAPI) {
+				actual = append(actual, fmt.Sprintf("%s %s breaking=%t", change.Name, change.Change, change.Breaking))
+			}
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("Expected changes %v but got %v", tc.expected, actual)
+			}
+		})
+	}
+
+	api := parseTestAPI(t, "package p\nconst (\n\tA = 1 << iota\n\tB\n)\n")
+	if expected := "const B = 1 << 1"; api["B"].Signature != expected {
+		t.Fatalf("Expected signature %q but got %q", expected, api["B"].Signature)
+	}
+}
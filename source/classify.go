@@ -0,0 +1,119 @@
+package source
+
+import (
+	"bytes"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"reflect"
+	"strings"
+)
+
+// Change classifications, from least to most significant.
+const (
+	ChangeFormatting = "formatting" // Only whitespace or formatting changed
+	ChangeComment    = "comment"    // Only comments, and possibly formatting, changed
+	ChangeTest       = "test"       // Code in test files changed
+	ChangeCode       = "code"       // Code or non-Go files changed
+)
+
+var changeRanks = map[string]int{
+	ChangeFormatting: 1,
+	ChangeComment:    2,
+	ChangeTest:       3,
+	ChangeCode:       4,
+}
+
+// MostSignificantChange returns the most significant of the given classifications.
+// An empty string is returned if there are none.
+func MostSignificantChange(classifications ...string) string {
+	most := ""
+	for _, classification := range classifications {
+		if changeRanks[classification] > changeRanks[most] {
+			most = classification
+		}
+	}
+
+	return most
+}
+
+// ClassifyChange classifies the change between two versions of a file.
+// Nil contents mean the file does not exist in that version.
+// Non-Go files, and Go files that were added, removed or cannot be scanned, are
+// classified as code changes (or test changes for test files).
+//
+// Directive comments such as //go:build and //go:embed, and comments in files
+// using cgo, are treated as code.
+func ClassifyChange(file string, oldBody, newBody []byte) string {
+	if !strings.HasSuffix(file, ".go") {
+		return ChangeCode
+	}
+
+	codeChange := ChangeCode
+	if strings.HasSuffix(file, "_test.go") {
+		codeChange = ChangeTest
+	}
+	if oldBody == nil || newBody == nil {
+		return codeChange
+	}
+
+	oldCode, oldErr := goTokens(oldBody, false)
+	newCode, newErr := goTokens(newBody, false)
+	if oldErr != nil || newErr != nil || !reflect.DeepEqual(oldCode, newCode) {
+		return codeChange
+	}
+
+	oldAll, oldErr := goTokens(oldBody, true)
+	newAll, newErr := goTokens(newBody, true)
+	if oldErr != nil || newErr != nil || !reflect.DeepEqual(oldAll, newAll) {
+		return ChangeComment
+	}
+
+	return ChangeFormatting
+}
+
+// goTokens returns the tokens of a Go source file, ignoring whitespace.
+// Comments are only included if withComments is true, unless they are directives
+// or the file uses cgo.
+func goTokens(body []byte, withComments bool) ([]string, error) {
+	withComments = withComments || bytes.Contains(body, []byte(`"C"`))
+
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(body))
+
+	var scanErr error
+	var s scanner.Scanner
+	s.Init(file, body, func(pos token.Position, msg string) {
+		scanErr = fmt.Errorf("%s: %s", pos, msg)
+	}, scanner.ScanComments)
+
+	var tokens []string
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+
+		switch tok {
+		case token.COMMENT:
+			if withComments || isDirective(lit) {
+				tokens = append(tokens, strings.TrimSpace(lit))
+			}
+		case token.SEMICOLON:
+			// Automatically inserted semicolons have a newline literal.
+			tokens = append(tokens, tok.String())
+		default:
+			tokens = append(tokens, tok.String()+lit)
+		}
+	}
+
+	return tokens, scanErr
+}
+
+// isDirective returns true if a comment is a build or compiler directive.
+func isDirective(comment string) bool {
+	return strings.HasPrefix(comment, "//go:") ||
+		strings.HasPrefix(comment, "// +build") ||
+		strings.HasPrefix(comment, "//export ") ||
+		strings.HasPrefix(comment, "//line ")
+}
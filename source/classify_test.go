@@ -0,0 +1,110 @@
+package source
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestClassifyChange(t *testing.T) {
+	const base = "package p\n\n// Sum adds.\nfunc Sum(a, b int) int {\n\treturn a + b\n}\n"
+
+	testCases := []struct {
+		name     string
+		file     string
+		oldBody  string
+		newBody  string
+		expected string
+	}{
+		{
+			name:     "comment only",
+			file:     "p.go",
+			oldBody:  base,
+			newBody:  "package p\n\n// Sum returns the sum.\nfunc Sum(a, b int) int {\n\treturn a + b // add\n}\n",
+			expected: ChangeComment,
+		},
+		{
+			name:     "whitespace only",
+			file:     "p.go",
+			oldBody:  base,
+			newBody:  "package p\n\n// Sum adds.\nfunc Sum(a, b int) int {\n\n\treturn a+b\n}\n",
+			expected: ChangeFormatting,
+		},
+		{
+			name:     "gofmt only",
+			file:     "p.go",
+			oldBody:  "package p\nfunc Sum(a,b int)int{return a+b}\n",
+			newBody:  "package p\n\nfunc Sum(a, b int) int { return a + b }\n",
+			expected: ChangeFormatting,
+		},
+		{
+			name:     "code change",
+			file:     "p.go",
+			oldBody:  base,
+			newBody:  "package p\n\n// Sum adds.\nfunc Sum(a, b int) int {\n\treturn b + a\n}\n",
+			expected: ChangeCode,
+		},
+		{
+			name:     "code change in test file",
+			file:     "p_test.go",
+			oldBody:  base,
+			newBody:  "package p\n\n// Sum adds.\nfunc Sum(a, b int) int {\n\treturn b + a\n}\n",
+			expected: ChangeTest,
+		},
+		{
+			name:     "directive comment",
+			file:     "p.go",
+			oldBody:  base,
+			newBody:  "//go:build linux\n\n" + base,
+			expected: ChangeCode,
+		},
+		{
+			name:     "added file",
+			file:     "p.go",
+			newBody:  base,
+			expected: ChangeCode,
+		},
+		{
+			name:     "non-Go file",
+			file:     "README.md",
+			oldBody:  "# Title\n",
+			newBody:  "# Title \n",
+			expected: ChangeCode,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("case=%s", tc.name), func(t *testing.T) {
+			var oldBody, newBody []byte
+			if len(tc.oldBody) > 0 {
+				oldBody = []byte(tc.oldBody)
+			}
+			if len(tc.newBody) > 0 {
+				newBody = []byte(tc.newBody)
+			}
+			if actual := ClassifyChange(tc.file, oldBody, newBody); actual != tc.expected {
+				t.Fatalf("Expected %s but got %s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMostSignificantChange(t *testing.T) {
+	testCases := []struct {
+		classifications []string
+		expected        string
+	}{
+		{classifications: nil, expected: ""},
+		{classifications: []string{ChangeFormatting}, expected: ChangeFormatting},
+		{classifications: []string{ChangeComment, ChangeFormatting}, expected: ChangeComment},
+		{classifications: []string{ChangeFormatting, ChangeTest, ChangeComment}, expected: ChangeTest},
+		{classifications: []string{ChangeTest, ChangeCode, ChangeComment}, expected: ChangeCode},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("classifications=%v", tc.classifications), func(t *testing.T) {
+			if actual := MostSignificantChange(tc.classifications...); actual != tc.expected {
+				t.Fatalf("Expected %s but got %s", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package source
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SymbolKey returns the key identifying a package-level declaration.
+// Functions, types, variables and constants are keyed as "importPath.Name", and
+// methods are keyed as "importPath.Type.Method".
+func SymbolKey(importPath string, names ...string) string {
+	return strings.Join(append([]string{importPath}, names...), ".")
+}
+
+// DeclKeys returns the symbol keys of all names declared by a package-level declaration.
+// Import declarations have no keys.
+func DeclKeys(importPath string, decl ast.Decl) []string {
+	var keys []string
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if recv := ReceiverTypeName(d); len(recv) > 0 {
+			keys = append(keys, SymbolKey(importPath, recv, d.Name.Name))
+		} else {
+			keys = append(keys, SymbolKey(importPath, d.Name.Name))
+		}
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				keys = append(keys, SymbolKey(importPath, s.Name.Name))
+			case *ast.ValueSpec:
+				for _, name := range s.Names {
+					keys = append(keys, SymbolKey(importPath, name.Name))
+				}
+			}
+		}
+	}
+
+	return keys
+}
+
+// ReceiverTypeName returns the name of the receiver type of a method declaration,
+// without any pointer or type parameters. An empty string is returned for functions.
+func ReceiverTypeName(decl *ast.FuncDecl) string {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return ""
+	}
+
+	expr := decl.Recv.List[0].Type
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// Units splits a package-level declaration into the units that are tracked as
+// separate symbols. Type and variable declarations are split into one declaration
+// per spec, but constant groups are kept whole since the value of each constant
+// may depend on its position in the group (e.g. iota).
+func Units(decl ast.Decl) []ast.Decl {
+	genDecl, ok := decl.(*ast.GenDecl)
+	if !ok || genDecl.Tok == token.CONST || genDecl.Tok == token.IMPORT {
+		return []ast.Decl{decl}
+	}
+
+	units := make([]ast.Decl, 0, len(genDecl.Specs))
+	for _, spec := range genDecl.Specs {
+		units = append(units, &ast.GenDecl{Tok: genDecl.Tok, Specs: []ast.Spec{spec}})
+	}
+
+	return units
+}
+
+// Decls returns the source text of every package-level declaration in the given files,
+// keyed by symbol key. Files should be parsed without comments so that documentation
+// changes do not show up as declaration changes.
+// Declarations sharing a key, such as init functions and blank variables, have their texts
+// joined in file name and source order.
+func Decls(importPath string, fset *token.FileSet, files []*ast.File) (map[string]string, error) {
+	files = append([]*ast.File(nil), files...)
+	sort.SliceStable(files, func(i, j int) bool {
+		return fset.Position(files[i].Pos()).Filename < fset.Position(files[j].Pos()).Filename
+	})
+
+	decls := make(map[string]string)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			for _, unit := range Units(decl) {
+				keys := DeclKeys(importPath, unit)
+				if len(keys) == 0 {
+					continue
+				}
+
+				text, err := nodeText(fset, unit)
+				if err != nil {
+					return nil, err
+				}
+				for _, key := range keys {
+					if existing, ok := decls[key]; ok {
+						decls[key] = existing + "\n\n" + text
+					} else {
+						decls[key] = text
+					}
+				}
+			}
+		}
+	}
+
+	return decls, nil
+}
+
+// ParseDecls parses the given file contents and returns their package-level declarations.
+// Nil contents are treated as a file that does not exist.
+func ParseDecls(importPath string, contents map[string][]byte) (map[string]string, error) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for name, body := range contents {
+		if body == nil {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, body, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	return Decls(importPath, fset, files)
+}
+
+// Import is an import declared by a Go file.
+type Import struct {
+	Path string // Import path
+	Line int    // Line of the import path in the file
+}
+
+// Imports returns the imports of a Go file, in the order they are declared.
+// Nil contents are treated as a file that does not exist.
+func Imports(body []byte) ([]Import, error) {
+	if body == nil {
+		return nil, nil
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", body, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	var imports []Import
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, err
+		}
+		imports = append(imports, Import{Path: importPath, Line: fset.Position(spec.Path.Pos()).Line})
+	}
+
+	return imports, nil
+}
+
+// ChangedDecls returns the sorted keys of declarations that were added, removed or
+// modified between two sets of declarations.
+func ChangedDecls(oldDecls, newDecls map[string]string) []string {
+	var changed []string
+	for key, oldText := range oldDecls {
+		if newText, ok := newDecls[key]; !ok || newText != oldText {
+			changed = append(changed, key)
+		}
+	}
+	for key := range newDecls {
+		if _, ok := oldDecls[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+
+	return changed
+}
+
+func nodeText(fset *token.FileSet, node interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,69 @@
+package source
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestChangedDeclsRepeatedNames(t *testing.T) {
+	oldContents := map[string][]byte{
+		"a.go": []byte("package p\nfunc init() { a() }\nvar _ = 1\nfunc a() {}\n"),
+		"b.go": []byte("package p\nfunc init() { b() }\nvar _ = 2\nfunc b() {}\n"),
+	}
+
+	testCases := []struct {
+		name     string
+		contents map[string][]byte
+		expected []string
+	}{
+		{
+			name:     "unchanged",
+			contents: oldContents,
+			expected: nil,
+		},
+		{
+			name: "second init changed",
+			contents: map[string][]byte{
+				"a.go": oldContents["a.go"],
+				"b.go": []byte("package p\nfunc init() { b(); b() }\nvar _ = 2\nfunc b() {}\n"),
+			},
+			expected: []string{"p.init"},
+		},
+		{
+			name: "second blank var changed",
+			contents: map[string][]byte{
+				"a.go": oldContents["a.go"],
+				"b.go": []byte("package p\nfunc init() { b() }\nvar _ = 3\nfunc b() {}\n"),
+			},
+			expected: []string{"p._"},
+		},
+		{
+			name: "init removed",
+			contents: map[string][]byte{
+				"a.go": oldContents["a.go"],
+				"b.go": []byte("package p\nvar _ = 2\nfunc b() {}\n"),
+			},
+			expected: []string{"p.init"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("case=%s", tc.name), func(t *testing.T) {
+			// Parse repeatedly, since map iteration order must not change the result.
+			for i := 0; i < 10; i++ {
+				oldDecls, err := ParseDecls("p", oldContents)
+				if err != nil {
+					t.Fatal(err)
+				}
+				newDecls, err := ParseDecls("p", tc.contents)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if actual := ChangedDecls(oldDecls, newDecls); !reflect.DeepEqual(tc.expected, actual) {
+					t.Fatalf("Expected %v but got %v", tc.expected, actual)
+				}
+			}
+		})
+	}
+}
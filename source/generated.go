@@ -0,0 +1,55 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+var (
+	generatedHeader = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+	protoImport     = regexp.MustCompile(`^\s*import\s+(?:public\s+|weak\s+)?"([^"]+)"\s*;`)
+)
+
+// GeneratedSource returns the file a generated Go file was generated from, as named by the
+// "// source: file" comment that protoc plugins write into the file header.
+// An empty string is returned for files without the standard "// Code generated ... DO NOT EDIT."
+// header or without a source comment.
+func GeneratedSource(body []byte) string {
+	generated := false
+	source := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+
+		if generatedHeader.MatchString(line) {
+			generated = true
+		} else if strings.HasPrefix(line, "// source:") {
+			source = strings.TrimSpace(strings.TrimPrefix(line, "// source:"))
+		}
+	}
+
+	if !generated {
+		return ""
+	}
+
+	return source
+}
+
+// ProtoImports returns the files imported by a .proto file, as written in its import statements.
+func ProtoImports(body []byte) []string {
+	var imports []string
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		if match := protoImport.FindStringSubmatch(scanner.Text()); match != nil {
+			imports = append(imports, match[1])
+		}
+	}
+
+	return imports
+}
@@ -0,0 +1,62 @@
+package source
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestGeneratedSource(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "protoc-gen-go",
+			body:     "// Code generated by protoc-gen-go. DO NOT EDIT.\n// versions:\n// \tprotoc v3.21.12\n// source: api/v1/api.proto\n\npackage v1\n",
+			expected: "api/v1/api.proto",
+		},
+		{
+			name:     "not generated",
+			body:     "// source: api/v1/api.proto\n\npackage v1\n",
+			expected: "",
+		},
+		{
+			name:     "no source",
+			body:     "// Code generated by stringer. DO NOT EDIT.\n\npackage v1\n",
+			expected: "",
+		},
+		{
+			name:     "source after package clause",
+			body:     "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage v1\n\n// source: api/v1/api.proto\n",
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("case=%s", tc.name), func(t *testing.T) {
+			if actual := GeneratedSource([]byte(tc.body)); actual != tc.expected {
+				t.Fatalf("Expected %q but got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestProtoImports(t *testing.T) {
+	body := `syntax = "proto3";
+
+package api.v1;
+
+import "google/protobuf/empty.proto";
+import public "api/v1/types.proto";
+  import weak "api/v1/legacy.proto";
+// import "commented/out.proto";
+
+message Request {}
+`
+	expected := []string{"google/protobuf/empty.proto", "api/v1/types.proto", "api/v1/legacy.proto"}
+	if actual := ProtoImports([]byte(body)); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %v but got %v", expected, actual)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/alecholmes/tdiff/app"
+)
+
+// why runs the "why" command, which explains how a package reaches another package.
+func why(args []string) {
+	flags := flag.NewFlagSet("why", flag.ExitOnError)
+	packageFlag := flags.String("package", "", "Root package")
+	targetFlag := flags.String("target", "", "Package to explain the reachability of from the root package")
+	maxChainsFlag := flags.Int("max-paths", 10, "Maximum number of import chains printed; shortest chains come first")
+	maxChainLengthFlag := flags.Int("max-path-length", 0, "If positive, the maximum number of packages in printed import chains")
+	generateFlag := flags.Bool("generate", false, "If set, packages run by //go:generate directives are treated as imports")
+	jsonFlag := flags.Bool("json", false, "If set, the explanation is printed as JSON")
+	flags.Parse(args)
+
+	if len(*packageFlag) == 0 || len(*targetFlag) == 0 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	explanation, err := app.Why(*packageFlag, *targetFlag, app.WhyOptions{
+		MaxChains:      *maxChainsFlag,
+		MaxChainLength: *maxChainLengthFlag,
+		Generate:       *generateFlag,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *jsonFlag {
+		body, err := json.MarshalIndent(explanation, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(body))
+		return
+	}
+
+	if len(explanation.Chains) == 0 {
+		if *maxChainLengthFlag > 0 {
+			fmt.Printf("%s does not reach %s within %d packages\n", explanation.RootImportPath, explanation.Target, *maxChainLengthFlag)
+		} else {
+			fmt.Printf("%s does not reach %s\n", explanation.RootImportPath, explanation.Target)
+		}
+		return
+	}
+
+	for i, chain := range explanation.Chains {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(strings.Join(chain, " > "))
+		for j := 1; j < len(chain); j++ {
+			fmt.Printf("  %s imports %s\n", chain[j-1], chain[j])
+			for _, site := range explanation.Import(chain[j-1], chain[j]).Sites {
+				fmt.Printf("    %s:%d (%s)\n", site.File, site.Line, site.Kind)
+			}
+		}
+	}
+}